@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureFile - меню и заказы в YAML или JSON. Имена полей совпадают с
+// ответами BFF, чтобы данные можно было скопировать из них.
+type fixtureFile struct {
+	Dishes []dishFixture  `yaml:"dishes" json:"dishes,omitempty"`
+	Orders []orderFixture `yaml:"orders" json:"orders"`
+}
+
+type namedRef struct {
+	ID   int32  `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"`
+}
+
+type nutritionFixture struct {
+	Calories      float32 `yaml:"calories" json:"calories"`
+	Proteins      float32 `yaml:"proteins" json:"proteins"`
+	Fats          float32 `yaml:"fats" json:"fats"`
+	Carbohydrates float32 `yaml:"carbohydrates" json:"carbohydrates"`
+}
+
+type dishFixture struct {
+	ID        int32            `yaml:"id" json:"id"`
+	Name      string           `yaml:"name" json:"name"`
+	Type      namedRef         `yaml:"type" json:"type"`
+	Category  namedRef         `yaml:"category" json:"category"`
+	Nutrition nutritionFixture `yaml:"nutrition" json:"nutrition"`
+	Tag       namedRef         `yaml:"tag" json:"tag"`
+	Recipe    string           `yaml:"recipe" json:"recipe"`
+	// Price - цена в минимальных единицах валюты
+	Price    int64  `yaml:"price" json:"price"`
+	Currency string `yaml:"currency" json:"currency,omitempty"`
+}
+
+type orderItemFixture struct {
+	DishID    int64    `yaml:"dish_id" json:"dish_id"`
+	Quantity  int32    `yaml:"quantity" json:"quantity"`
+	Notes     string   `yaml:"notes" json:"notes,omitempty"`
+	Modifiers []string `yaml:"modifiers" json:"modifiers,omitempty"`
+}
+
+type orderFixture struct {
+	ID         uint64             `yaml:"id" json:"id"`
+	UserID     uint64             `yaml:"user_id" json:"user_id"`
+	Status     string             `yaml:"status" json:"status"`
+	Items      []int64            `yaml:"items" json:"items,omitempty"`
+	OrderItems []orderItemFixture `yaml:"order_items" json:"order_items,omitempty"`
+}
+
+// loadFixtures читает файл; JSON разбирается как YAML.
+func loadFixtures(path string) (*fixtureFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixtureFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	seen := make(map[int32]bool, len(f.Dishes))
+	for _, dish := range f.Dishes {
+		if dish.ID <= 0 {
+			return nil, fmt.Errorf("%s: dish %q has no positive id", path, dish.Name)
+		}
+		if seen[dish.ID] {
+			return nil, fmt.Errorf("%s: duplicate dish id %d", path, dish.ID)
+		}
+		seen[dish.ID] = true
+	}
+	return &f, nil
+}
+
+// saveOrders атомарно записывает заказы в JSON: файл заменяется целиком,
+// чтобы при остановке процесса не остался наполовину записанный файл.
+func saveOrders(path string, orders []*pbOrders.OrderResponse) error {
+	f := fixtureFile{Orders: make([]orderFixture, 0, len(orders))}
+	for _, o := range orders {
+		f.Orders = append(f.Orders, toOrderFixture(o))
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d dishFixture) proto() *pbDishes.Dish {
+	return &pbDishes.Dish{
+		Id:       d.ID,
+		Name:     d.Name,
+		Type:     &pbDishes.Type{Id: d.Type.ID, TypeDish: d.Type.Name},
+		Category: &pbDishes.Category{Id: d.Category.ID, CategoryDish: d.Category.Name},
+		NutFact: &pbDishes.NutritionFact{
+			Id:            d.ID,
+			Calories:      d.Nutrition.Calories,
+			Proteins:      d.Nutrition.Proteins,
+			Fats:          d.Nutrition.Fats,
+			Carbohydrates: d.Nutrition.Carbohydrates,
+		},
+		Tag:      &pbDishes.Tag{Id: d.Tag.ID, TagDish: d.Tag.Name},
+		Recipe:   d.Recipe,
+		Price:    d.Price,
+		Currency: d.Currency,
+	}
+}
+
+func (o orderFixture) proto() *pbOrders.OrderResponse {
+	resp := &pbOrders.OrderResponse{
+		Id:     o.ID,
+		UserId: o.UserID,
+		Items:  o.Items,
+		Status: o.Status,
+	}
+	for _, item := range o.OrderItems {
+		resp.OrderItems = append(resp.OrderItems, &pbOrders.OrderItem{
+			DishId:    item.DishID,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			Modifiers: item.Modifiers,
+		})
+	}
+	return resp
+}
+
+func toOrderFixture(o *pbOrders.OrderResponse) orderFixture {
+	f := orderFixture{
+		ID:     o.Id,
+		UserID: o.UserId,
+		Status: o.Status,
+		Items:  o.Items,
+	}
+	for _, item := range o.OrderItems {
+		f.OrderItems = append(f.OrderItems, orderItemFixture{
+			DishID:    item.DishId,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			Modifiers: item.Modifiers,
+		})
+	}
+	return f
+}
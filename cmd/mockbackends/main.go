@@ -0,0 +1,233 @@
+// Команда mockbackends запускает DishService и OrderService в памяти, чтобы
+// BFF можно было запустить локально без настоящих бэкендов.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/logging"
+	"github.com/anyviewww/bff-service/internal/testing/fakes"
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type options struct {
+	menuAddr  string
+	orderAddr string
+	fixtures  string
+	state     string
+	logLevel  string
+
+	latency      time.Duration
+	errorRate    float64
+	errorCode    string
+	errorMethods string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.menuAddr, "menu-addr", ":50051", "listen address of DishService")
+	flag.StringVar(&opts.orderAddr, "order-addr", ":50052", "listen address of OrderService")
+	flag.StringVar(&opts.fixtures, "fixtures", "", "YAML or JSON file with dishes and orders; built-in menu if empty")
+	flag.StringVar(&opts.state, "state", "mock-orders.json", "file where orders are saved after every change; empty disables saving")
+	flag.StringVar(&opts.logLevel, "log-level", "info", "log level: debug, info, warn, error")
+	flag.DurationVar(&opts.latency, "latency", 0, "delay added to every call")
+	flag.Float64Var(&opts.errorRate, "error-rate", 0, "share of calls that fail, from 0 to 1")
+	flag.StringVar(&opts.errorCode, "error-code", "UNAVAILABLE", "gRPC code of simulated errors")
+	flag.StringVar(&opts.errorMethods, "error-methods", "", "comma-separated methods affected by -latency and -error-rate, e.g. GetDishes,CreateOrder; all if empty")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	logger, err := logging.New(opts.logLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	dishes, orders, err := loadServices(opts)
+	if err != nil {
+		return err
+	}
+	if err := applyFaults(opts, dishes, orders); err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(logCalls), grpc.ChainStreamInterceptor(logStreams))
+	fakes.Register(srv, dishes, orders)
+	reflection.Register(srv)
+
+	// Оба сервиса доступны на обоих адресах, адреса могут совпадать
+	addrs := []string{opts.menuAddr}
+	if opts.orderAddr != opts.menuAddr {
+		addrs = append(addrs, opts.orderAddr)
+	}
+	errs := make(chan error, len(addrs))
+	for _, addr := range addrs {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		go func() { errs <- srv.Serve(lis) }()
+	}
+	slog.Info("mock backends started",
+		slog.String("menu_addr", opts.menuAddr),
+		slog.String("order_addr", opts.orderAddr),
+		slog.Int("orders", len(orders.Orders())),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		return err
+	}
+
+	slog.Info("shutting down mock backends")
+	// Открытые потоки WatchOrder не дали бы GracefulStop завершиться
+	srv.Stop()
+	return nil
+}
+
+// loadServices создаёт сервисы из файла фикстур. Сохранённые заказы из
+// файла состояния заменяют заказы из фикстур.
+func loadServices(opts options) (*fakes.DishService, *fakes.OrderService, error) {
+	dishes := fakes.NewDishService(fakes.Dishes()...)
+	orders := fakes.NewOrderService()
+
+	if opts.fixtures != "" {
+		f, err := loadFixtures(opts.fixtures)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(f.Dishes) > 0 {
+			menu := make([]*pbDishes.Dish, 0, len(f.Dishes))
+			for _, dish := range f.Dishes {
+				menu = append(menu, dish.proto())
+			}
+			dishes.SetDishes(menu...)
+		}
+		for _, o := range f.Orders {
+			orders.Seed(o.proto())
+		}
+	}
+
+	if opts.state == "" {
+		return dishes, orders, nil
+	}
+
+	state, err := loadFixtures(opts.state)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, nil, err
+	default:
+		orders = fakes.NewOrderService()
+		for _, o := range state.Orders {
+			orders.Seed(o.proto())
+		}
+		slog.Info("orders restored", slog.String("file", opts.state), slog.Int("orders", len(state.Orders)))
+	}
+
+	// Снимок берётся под mu, поэтому последним записывается последнее
+	// состояние, даже если изменения идут параллельно
+	var mu sync.Mutex
+	orders.OnChange(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := saveOrders(opts.state, orders.Orders()); err != nil {
+			slog.Error("failed to save orders", slog.String("file", opts.state), slog.Any("error", err))
+		}
+	})
+	return dishes, orders, nil
+}
+
+// applyFaults настраивает задержку и ошибки из флагов.
+func applyFaults(opts options, dishes *fakes.DishService, orders *fakes.OrderService) error {
+	if opts.errorRate < 0 || opts.errorRate > 1 {
+		return fmt.Errorf("-error-rate must be between 0 and 1")
+	}
+
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(opts.errorCode)))); err != nil || code == codes.OK {
+		return fmt.Errorf("invalid -error-code %q", opts.errorCode)
+	}
+
+	fault := fakes.Fault{Delay: opts.latency}
+	if opts.errorRate > 0 {
+		fault.Code = code
+		fault.Message = "simulated error"
+		fault.Probability = opts.errorRate
+	}
+	if fault.Delay == 0 && fault.Code == codes.OK {
+		return nil
+	}
+
+	methods := []string{fakes.AllMethods}
+	if opts.errorMethods != "" {
+		methods = strings.Split(opts.errorMethods, ",")
+	}
+	for _, method := range methods {
+		method = strings.TrimSpace(method)
+		dishes.Fail(method, fault)
+		orders.Fail(method, fault)
+	}
+	slog.Info("simulating faults",
+		slog.Duration("latency", opts.latency),
+		slog.Float64("error_rate", opts.errorRate),
+		slog.String("error_code", code.String()),
+		slog.Any("methods", methods),
+	)
+	return nil
+}
+
+func logCalls(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+func logStreams(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(info.FullMethod, start, err)
+	return err
+}
+
+func logCall(method string, start time.Time, err error) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	slog.Log(context.Background(), level, "grpc call",
+		slog.String("method", path.Base(method)),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,37 +13,221 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/anyviewww/bff-service/internal/api"
+	"github.com/anyviewww/bff-service/internal/auth"
+	"github.com/anyviewww/bff-service/internal/cart"
 	"github.com/anyviewww/bff-service/internal/client"
 	"github.com/anyviewww/bff-service/internal/config"
+	"github.com/anyviewww/bff-service/internal/health"
+	"github.com/anyviewww/bff-service/internal/idempotency"
+	"github.com/anyviewww/bff-service/internal/logging"
+	"github.com/anyviewww/bff-service/internal/metrics"
+	"github.com/anyviewww/bff-service/internal/pricing"
+	"github.com/anyviewww/bff-service/internal/ratelimit"
+	"github.com/anyviewww/bff-service/internal/tlsconfig"
+	"github.com/anyviewww/bff-service/internal/tracing"
+	"github.com/anyviewww/bff-service/internal/watch"
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger, err := logging.New(cfg.Log.Level)
+	if err != nil {
+		logging.Fatal("failed to set up logging", slog.Any("error", err))
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter: cfg.Tracing.Exporter,
+		FilePath: cfg.Tracing.File,
+	})
+	if err != nil {
+		logging.Fatal("failed to set up tracing", slog.Any("error", err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", slog.Any("error", err))
+		}
+	}()
+
+	callOpts := client.CallOptions{
+		Timeout:        cfg.GRPC.Timeout,
+		MethodTimeouts: cfg.GRPC.MethodTimeouts,
+		MaxRetries:     cfg.GRPC.MaxRetries,
+		RetryBackoff:   cfg.GRPC.RetryBackoff,
+	}
+
+	m := metrics.New()
 
 	// Инициализация gRPC соединений
-	menuConn := createGRPCConnection(cfg.MenuServiceAddr)
-	defer menuConn.Close()
+	menuBreaker := client.NewCircuitBreaker("menu", cfg.Breaker.FailureThreshold, cfg.Breaker.OpenTimeout)
+	orderBreaker := client.NewCircuitBreaker("order", cfg.Breaker.FailureThreshold, cfg.Breaker.OpenTimeout)
+	m.RegisterBreaker(menuBreaker)
+	m.RegisterBreaker(orderBreaker)
+
+	// Сертификаты перечитываются в фоне до завершения процесса
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
 
-	orderConn := createGRPCConnection(cfg.OrderServiceAddr)
-	defer orderConn.Close()
+	menuConn := createGRPCConnection(watchCtx, "menu", cfg.Menu, cfg.CertReload.Interval, backendCallOptions(callOpts, "menu", m), menuBreaker)
+	orderConn := createGRPCConnection(watchCtx, "order", cfg.Order, cfg.CertReload.Interval, backendCallOptions(callOpts, "order", m), orderBreaker)
 
 	// Создание клиентов
 	menuClient := client.NewMenuClient(menuConn)
+	defer menuClient.Close()
+
 	orderClient := client.NewOrderClient(orderConn)
+	defer orderClient.Close()
+
+	readiness := health.NewChecker(cfg.Readiness.Timeout, cfg.Readiness.GRPCHealthCheck,
+		health.Dependency{Name: "menu", Conn: menuConn},
+		health.Dependency{Name: "order", Conn: orderConn},
+	)
+
+	var dishService pbDishes.DishServiceClient = menuClient
+	handlerOpts := []api.Option{
+		api.WithIdempotencyStore(idempotency.NewMemoryStore(cfg.Idempotency.TTL, cfg.Server.WriteTimeout)),
+		api.WithCartStore(cart.NewMemoryStore(cfg.Cart.TTL)),
+		api.WithReadiness(readiness),
+	}
+
+	calculator, err := pricing.NewCalculator(pricing.Options{
+		Currency:         cfg.Pricing.Currency,
+		TaxRate:          cfg.Pricing.TaxRate,
+		CategoryTaxRates: cfg.Pricing.CategoryTaxRates,
+	})
+	if err != nil {
+		logging.Fatal("failed to configure pricing", slog.Any("error", err))
+	}
+	handlerOpts = append(handlerOpts, api.WithPricing(calculator))
+
+	// Подписки на изменения заказов закрываются до остановки HTTP-сервера,
+	// иначе открытые потоки SSE не дадут ему завершиться
+	orderWatcher := watch.NewWatcher(orderClient)
+	handlerOpts = append(handlerOpts, api.WithOrderEvents(orderWatcher, api.OrderEventsOptions{
+		Heartbeat:      cfg.OrderEvents.Heartbeat,
+		AllowedOrigins: cfg.CORS.AllowedOrigins,
+	}))
+
+	if cfg.RateLimit.Enabled {
+		handlerOpts = append(handlerOpts, api.WithRateLimit(ratelimit.NewMemoryStore(), api.RateLimitOptions{
+			Menu: ratelimit.Limit{
+				Rate:  cfg.RateLimit.Menu.RequestsPerSecond,
+				Burst: cfg.RateLimit.Menu.Burst,
+			},
+			Orders: ratelimit.Limit{
+				Rate:  cfg.RateLimit.Orders.RequestsPerSecond,
+				Burst: cfg.RateLimit.Orders.Burst,
+			},
+			APIKeys: cfg.RateLimit.APIKeys,
+		}))
+	}
+
+	if cfg.GraphQL.Enabled {
+		handlerOpts = append(handlerOpts, api.WithGraphQL(api.GraphQLOptions{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		}))
+	}
+
+	if cfg.Auth.Enabled {
+		verifier, err := auth.NewVerifier(auth.Options{
+			HMACSecret:    cfg.Auth.HS256Secret,
+			PublicKeyFile: cfg.Auth.PublicKeyFile,
+			JWKSFile:      cfg.Auth.JWKSFile,
+			Issuer:        cfg.Auth.Issuer,
+			Audience:      cfg.Auth.Audience,
+			AdminRole:     cfg.Auth.AdminRole,
+		})
+		if err != nil {
+			logging.Fatal("failed to configure authentication", slog.Any("error", err))
+		}
+		handlerOpts = append(handlerOpts, api.WithAuth(verifier))
+	} else {
+		slog.Warn("authentication is disabled, orders are not scoped to users")
+		if cfg.Admin.AllowUnauthenticated {
+			slog.Warn("admin routes are open to everyone")
+			handlerOpts = append(handlerOpts, api.WithUnauthenticatedAdmin())
+		} else {
+			slog.Warn("admin routes are closed, set admin.allow_unauthenticated to open them")
+		}
+	}
+
+	if cfg.Cache.MenuTTL > 0 {
+		menuCache := client.NewMenuCache(menuClient, cfg.Cache.MenuTTL, cfg.Cache.MenuStaleTTL)
+		dishService = menuCache
+		handlerOpts = append(handlerOpts, api.WithMenuCache(menuCache))
+		m.RegisterMenuCache(menuCache)
+	}
 
 	// Настройка HTTP сервера
-	router := gin.Default()
-	apiHandler := api.NewHandler(menuClient, orderClient)
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logging.Fatal("invalid trusted proxies", slog.Any("error", err))
+	}
+	router.Use(
+		logging.RequestID(),
+		logging.AccessLog(),
+		gin.Recovery(),
+		otelgin.Middleware(tracing.ServiceName),
+		// Метрики до CORS, иначе прерванные им preflight-запросы не учитываются
+		m.GinMiddleware(),
+		api.CORS(api.CORSOptions{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		}),
+	)
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	apiHandler := api.NewHandler(dishService, orderClient, handlerOpts...)
 	apiRouter := api.NewRouter(apiHandler)
 	apiRouter.SetupRoutes(router)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
-		Handler: router,
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	serverTLS := cfg.Server.TLS.CertFile != ""
+	if serverTLS {
+		reloader, err := tlsconfig.NewReloader("server", tlsconfig.Files{
+			CertFile: cfg.Server.TLS.CertFile,
+			KeyFile:  cfg.Server.TLS.KeyFile,
+			CAFile:   cfg.Server.TLS.ClientCAFile,
+		})
+		if err != nil {
+			logging.Fatal("failed to load server certificates", slog.Any("error", err))
+		}
+		go reloader.Watch(watchCtx, cfg.CertReload.Interval)
+		srv.TLSConfig = tlsconfig.Server(reloader)
 	}
 
 	// Graceful shutdown
@@ -48,37 +235,72 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("Server started on port %s", cfg.ServerPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		slog.Info("server started", slog.String("port", cfg.Server.Port), slog.Bool("tls", serverTLS))
+		var err error
+		if serverTLS {
+			// Сертификат задаётся через srv.TLSConfig
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.Fatal("failed to start server", slog.Any("error", err))
 		}
 	}()
 
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("shutting down server")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Сначала перестаём быть готовыми и даём балансировщику время убрать
+	// сервис из ротации, затем закрываем listener
+	readiness.SetShuttingDown()
+	time.Sleep(cfg.Server.ShutdownDrainDelay)
+	orderWatcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logging.Fatal("server forced to shutdown", slog.Any("error", err))
 	}
+	apiHandler.WaitOrderStreams(ctx)
 
-	log.Println("Server exited properly")
+	slog.Info("server exited properly")
 }
 
-func createGRPCConnection(addr string) *grpc.ClientConn {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// backendCallOptions добавляет к вызовам бэкенда трейсинг, метрики и логи
+func backendCallOptions(opts client.CallOptions, backend string, m *metrics.Metrics) client.CallOptions {
+	opts.Interceptors = []grpc.UnaryClientInterceptor{
+		otelgrpc.UnaryClientInterceptor(),
+		m.UnaryClientInterceptor(backend),
+		logging.UnaryClientInterceptor(backend),
+	}
+	opts.StreamInterceptors = []grpc.StreamClientInterceptor{
+		otelgrpc.StreamClientInterceptor(),
+		m.StreamClientInterceptor(backend),
+		logging.StreamClientInterceptor(backend),
+	}
+	return opts
+}
 
-	conn, err := grpc.DialContext(
-		ctx,
-		addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+func createGRPCConnection(ctx context.Context, name string, backend config.BackendConfig, reloadInterval time.Duration, opts client.CallOptions, breaker *client.CircuitBreaker) *grpc.ClientConn {
+	var creds credentials.TransportCredentials
+	if backend.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(name, tlsconfig.Files{
+			CertFile: backend.TLS.CertFile,
+			KeyFile:  backend.TLS.KeyFile,
+			CAFile:   backend.TLS.CAFile,
+		})
+		if err != nil {
+			logging.Fatal("failed to load backend certificates", slog.String("backend", name), slog.Any("error", err))
+		}
+		go reloader.Watch(ctx, reloadInterval)
+		creds = tlsconfig.Client(reloader, backend.TLS.ServerName)
+	}
+
+	conn, err := client.Dial(backend.Addr, creds, opts, breaker)
 	if err != nil {
-		log.Fatalf("Failed to connect to gRPC service at %s: %v", addr, err)
+		logging.Fatal("failed to create gRPC connection", slog.String("addr", backend.Addr), slog.Any("error", err))
 	}
 
 	return conn
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anyviewww/bff-service/internal/cart"
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// WithCartStore включает эндпоинты корзины.
+func WithCartStore(store cart.Store) Option {
+	return func(h *Handler) {
+		h.cart = store
+	}
+}
+
+// AddCartItemRequest - тело POST /cart/items. Quantity по умолчанию 1.
+type AddCartItemRequest struct {
+	DishID   int64 `json:"dish_id" binding:"required" openapi:"minimum=1"`
+	Quantity *int  `json:"quantity,omitempty"`
+}
+
+// SetCartItemQuantityRequest - тело PUT /cart/items/:dish_id. Количество 0
+// удаляет позицию.
+type SetCartItemQuantityRequest struct {
+	Quantity *int `json:"quantity" binding:"required" openapi:"minimum=0"`
+}
+
+func (h *Handler) GetCart(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	userCart, err := h.cart.Get(c.Request.Context(), cartKey(userID))
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCartResponse(userID, userCart))
+}
+
+func (h *Handler) AddCartItem(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	var req AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, codes.InvalidArgument, err.Error())
+		return
+	}
+	quantity := 1
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+
+	if !h.validateCartDish(c, req.DishID) {
+		return
+	}
+
+	userCart, err := h.cart.Update(c.Request.Context(), cartKey(userID), func(uc *cart.Cart) error {
+		return uc.Add(req.DishID, quantity)
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCartResponse(userID, userCart))
+}
+
+func (h *Handler) SetCartItemQuantity(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	dishID, err := strconv.ParseInt(c.Param("dish_id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid dish ID format")
+		return
+	}
+
+	var req SetCartItemQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	// Удаление позиции не требует проверки блюда в меню
+	if *req.Quantity > 0 && !h.validateCartDish(c, dishID) {
+		return
+	}
+
+	userCart, err := h.cart.Update(c.Request.Context(), cartKey(userID), func(uc *cart.Cart) error {
+		return uc.SetQuantity(dishID, *req.Quantity)
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCartResponse(userID, userCart))
+}
+
+func (h *Handler) RemoveCartItem(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	dishID, err := strconv.ParseInt(c.Param("dish_id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid dish ID format")
+		return
+	}
+
+	userCart, err := h.cart.Update(c.Request.Context(), cartKey(userID), func(uc *cart.Cart) error {
+		if !uc.Remove(dishID) {
+			return cart.ErrItemNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCartResponse(userID, userCart))
+}
+
+func (h *Handler) ClearCart(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	userCart, err := h.cart.Update(c.Request.Context(), cartKey(userID), func(uc *cart.Cart) error {
+		uc.Clear()
+		return nil
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCartResponse(userID, userCart))
+}
+
+// CheckoutCart создаёт заказ из корзины и очищает её. На время создания
+// заказа корзина заблокирована, поэтому одновременное оформление или
+// изменение корзины не приводит к заказу с другим содержимым.
+func (h *Handler) CheckoutCart(c *gin.Context) {
+	userID, ok := h.cartOwner(c)
+	if !ok {
+		return
+	}
+
+	idem, handled := h.beginIdempotent(c, gin.H{"user_id": userID})
+	if handled {
+		return
+	}
+	defer h.releaseIdempotent(c, idem)
+
+	ctx := c.Request.Context()
+	key := cartKey(userID)
+
+	userCart, err := h.cart.BeginCheckout(ctx, key)
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	// abort возвращает корзину в обычное состояние после неудачи
+	abort := func() {
+		if err := h.cart.AbortCheckout(context.WithoutCancel(ctx), key); err != nil {
+			slog.ErrorContext(ctx, "failed to abort cart checkout", slog.Any("error", err))
+		}
+	}
+
+	if userCart.Empty() {
+		abort()
+		respondError(c, codes.FailedPrecondition, "Cart is empty")
+		return
+	}
+
+	// Блюда могли пропасть из меню после добавления в корзину
+	dishIDs := make([]int64, 0, len(userCart.Items))
+	for _, item := range userCart.Items {
+		dishIDs = append(dishIDs, item.DishID)
+	}
+	lookups := h.lookupDishes(ctx, dishIDs)
+
+	var unknown []string
+	for _, dishID := range dishIDs {
+		lookup := lookups[dishID]
+		if lookup.dish != nil {
+			continue
+		}
+		if lookup.code != codes.NotFound && lookup.code != codes.InvalidArgument {
+			abort()
+			respondError(c, lookup.code, lookup.err)
+			return
+		}
+		unknown = append(unknown, strconv.FormatInt(dishID, 10))
+	}
+	if len(unknown) > 0 {
+		abort()
+		respondError(c, codes.FailedPrecondition, "Cart contains unknown dishes: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	orderItems := make([]*pbOrders.OrderItem, 0, len(userCart.Items))
+	for _, item := range userCart.Items {
+		orderItems = append(orderItems, &pbOrders.OrderItem{
+			DishId:   item.DishID,
+			Quantity: int32(item.Quantity),
+		})
+	}
+
+	created, err := h.orderClient.CreateOrder(ctx, &pbOrders.CreateOrderRequest{
+		UserId:     userID,
+		Items:      flattenOrderItems(orderItems),
+		OrderItems: orderItems,
+	})
+	if err != nil {
+		abort()
+		respondGRPCError(c, err)
+		return
+	}
+
+	if err := h.cart.CompleteCheckout(context.WithoutCancel(ctx), key); err != nil {
+		// Заказ уже создан, поэтому ошибка очистки корзины не должна
+		// превращаться в ошибку запроса
+		slog.ErrorContext(ctx, "failed to clear cart after checkout",
+			slog.Uint64("order_id", created.Id), slog.Any("error", err))
+	}
+
+	h.completeIdempotent(c, idem, http.StatusCreated, h.orderResponse(ctx, created))
+}
+
+// cartOwner возвращает пользователя, чья корзина используется. Без
+// аутентификации пользователь передаётся параметром user_id.
+func (h *Handler) cartOwner(c *gin.Context) (uint64, bool) {
+	if h.cart == nil {
+		respondError(c, codes.Unimplemented, "Cart is not enabled")
+		return 0, false
+	}
+
+	if p, ok := principal(c); ok {
+		return p.UserID, true
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		respondError(c, codes.InvalidArgument, "user_id query parameter is required")
+		return 0, false
+	}
+	return userID, true
+}
+
+// validateCartDish проверяет, что блюдо есть в меню. При ошибке ответ уже
+// отправлен и возвращается false.
+func (h *Handler) validateCartDish(c *gin.Context, dishID int64) bool {
+	if dishID <= 0 || dishID > math.MaxInt32 {
+		respondError(c, codes.InvalidArgument, "Invalid dish ID")
+		return false
+	}
+
+	resp, err := h.menuClient.GetDishes(c.Request.Context(), &pbDishes.DishRequest{Id: int32(dishID)})
+	if err != nil {
+		respondGRPCError(c, err)
+		return false
+	}
+	if len(resp.Dishes) == 0 {
+		respondError(c, codes.InvalidArgument, fmt.Sprintf("Unknown dish %d", dishID))
+		return false
+	}
+	return true
+}
+
+func respondCartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, cart.ErrCheckoutInProgress):
+		respondError(c, codes.Aborted, "Cart checkout is in progress")
+	case errors.Is(err, cart.ErrItemNotFound):
+		respondError(c, codes.NotFound, "Dish is not in the cart")
+	case errors.Is(err, cart.ErrInvalidQuantity), errors.Is(err, cart.ErrTooManyItems):
+		respondError(c, codes.InvalidArgument, err.Error())
+	default:
+		slog.ErrorContext(c.Request.Context(), "cart store error", slog.Any("error", err))
+		respondError(c, codes.Unavailable, "Cart store is unavailable")
+	}
+}
+
+func cartKey(userID uint64) string {
+	return strconv.FormatUint(userID, 10)
+}
+
+func toCartResponse(userID uint64, userCart cart.Cart) CartResponse {
+	items := make([]CartItemResponse, 0, len(userCart.Items))
+	for _, item := range userCart.Items {
+		items = append(items, CartItemResponse{DishID: item.DishID, Quantity: item.Quantity})
+	}
+
+	result := CartResponse{
+		UserID:        userID,
+		Items:         items,
+		TotalQuantity: userCart.TotalQuantity(),
+	}
+	if !userCart.UpdatedAt.IsZero() {
+		result.UpdatedAt = &userCart.UpdatedAt
+	}
+	return result
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDishesLimit = 20
+	maxDishesLimit     = 100
+)
+
+type floatRange struct {
+	min *float64
+	max *float64
+}
+
+func (r floatRange) contains(v float64) bool {
+	if r.min != nil && v < *r.min {
+		return false
+	}
+	if r.max != nil && v > *r.max {
+		return false
+	}
+	return true
+}
+
+// dishQuery описывает фильтры, сортировку и пагинацию для GET /menu/dishes.
+// Вся обработка делается в BFF поверх полного списка из DishService.
+type dishQuery struct {
+	typeIDs     map[int32]bool
+	categoryIDs map[int32]bool
+	tagIDs      map[int32]bool
+	name        string
+
+	calories      floatRange
+	proteins      floatRange
+	fats          floatRange
+	carbohydrates floatRange
+
+	sortKey  string
+	sortDesc bool
+
+	limit  int
+	offset int
+}
+
+var dishSortKeys = map[string]func(d *pbDishes.Dish) float64{
+	"calories":      func(d *pbDishes.Dish) float64 { return float64(d.GetNutFact().GetCalories()) },
+	"proteins":      func(d *pbDishes.Dish) float64 { return float64(d.GetNutFact().GetProteins()) },
+	"fats":          func(d *pbDishes.Dish) float64 { return float64(d.GetNutFact().GetFats()) },
+	"carbohydrates": func(d *pbDishes.Dish) float64 { return float64(d.GetNutFact().GetCarbohydrates()) },
+}
+
+func parseDishQuery(c *gin.Context) (*dishQuery, error) {
+	q := &dishQuery{
+		name:    strings.ToLower(strings.TrimSpace(c.Query("q"))),
+		sortKey: "id",
+		limit:   defaultDishesLimit,
+	}
+
+	var err error
+	if q.typeIDs, err = parseIDSet(c.Query("type_id"), "type_id"); err != nil {
+		return nil, err
+	}
+	if q.categoryIDs, err = parseIDSet(c.Query("category_id"), "category_id"); err != nil {
+		return nil, err
+	}
+	if q.tagIDs, err = parseIDSet(c.Query("tag_id"), "tag_id"); err != nil {
+		return nil, err
+	}
+
+	ranges := []struct {
+		name string
+		dst  *floatRange
+	}{
+		{"calories", &q.calories},
+		{"proteins", &q.proteins},
+		{"fats", &q.fats},
+		{"carbohydrates", &q.carbohydrates},
+	}
+	for _, r := range ranges {
+		if r.dst.min, err = parseOptionalFloat(c, "min_"+r.name); err != nil {
+			return nil, err
+		}
+		if r.dst.max, err = parseOptionalFloat(c, "max_"+r.name); err != nil {
+			return nil, err
+		}
+		if r.dst.min != nil && r.dst.max != nil && *r.dst.min > *r.dst.max {
+			return nil, fmt.Errorf("min_%s must not be greater than max_%s", r.name, r.name)
+		}
+	}
+
+	if s := c.Query("sort"); s != "" {
+		if strings.HasPrefix(s, "-") {
+			q.sortDesc = true
+			s = s[1:]
+		}
+		if _, ok := dishSortKeys[s]; !ok && s != "id" && s != "name" {
+			return nil, fmt.Errorf("unsupported sort key %q", s)
+		}
+		q.sortKey = s
+	}
+
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxDishesLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxDishesLimit)
+		}
+		q.limit = limit
+	}
+
+	if s := c.Query("cursor"); s != "" {
+		offset, err := decodeCursor(s, q.fingerprint())
+		if err != nil {
+			return nil, err
+		}
+		q.offset = offset
+	}
+
+	return q, nil
+}
+
+func (q *dishQuery) matches(d *pbDishes.Dish) bool {
+	if len(q.typeIDs) > 0 && !q.typeIDs[d.GetType().GetId()] {
+		return false
+	}
+	if len(q.categoryIDs) > 0 && !q.categoryIDs[d.GetCategory().GetId()] {
+		return false
+	}
+	if len(q.tagIDs) > 0 && !q.tagIDs[d.GetTag().GetId()] {
+		return false
+	}
+	if q.name != "" && !strings.Contains(strings.ToLower(d.GetName()), q.name) {
+		return false
+	}
+
+	nf := d.GetNutFact()
+	return q.calories.contains(float64(nf.GetCalories())) &&
+		q.proteins.contains(float64(nf.GetProteins())) &&
+		q.fats.contains(float64(nf.GetFats())) &&
+		q.carbohydrates.contains(float64(nf.GetCarbohydrates()))
+}
+
+// apply возвращает страницу отфильтрованных и отсортированных блюд и курсор
+// следующей страницы (пустой, если страниц больше нет).
+func (q *dishQuery) apply(dishes []*pbDishes.Dish) ([]*pbDishes.Dish, string) {
+	filtered := make([]*pbDishes.Dish, 0, len(dishes))
+	for _, d := range dishes {
+		if q.matches(d) {
+			filtered = append(filtered, d)
+		}
+	}
+
+	less := q.lessFunc()
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if q.sortDesc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		// Tie-breaker по id, чтобы порядок между страницами был стабильным
+		return filtered[i].GetId() < filtered[j].GetId()
+	})
+
+	if q.offset >= len(filtered) {
+		return []*pbDishes.Dish{}, ""
+	}
+
+	end := q.offset + q.limit
+	if end >= len(filtered) {
+		return filtered[q.offset:], ""
+	}
+	return filtered[q.offset:end], encodeCursor(end, q.fingerprint())
+}
+
+// fingerprint - хэш фильтров и сортировки. Курсор действителен только для
+// запроса с тем же отпечатком, иначе смещение указывало бы на другую
+// страницу. limit в отпечаток не входит: его можно менять между страницами.
+func (q *dishQuery) fingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "type=%v\ncategory=%v\ntag=%v\nq=%q\n",
+		sortedIDs(q.typeIDs), sortedIDs(q.categoryIDs), sortedIDs(q.tagIDs), q.name)
+	for _, r := range []floatRange{q.calories, q.proteins, q.fats, q.carbohydrates} {
+		fmt.Fprintf(h, "range=%s,%s\n", formatBound(r.min), formatBound(r.max))
+	}
+	fmt.Fprintf(h, "sort=%s,%t\n", q.sortKey, q.sortDesc)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func sortedIDs(set map[int32]bool) []int32 {
+	ids := make([]int32, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func formatBound(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+func (q *dishQuery) lessFunc() func(a, b *pbDishes.Dish) bool {
+	switch q.sortKey {
+	case "name":
+		return func(a, b *pbDishes.Dish) bool {
+			return strings.ToLower(a.GetName()) < strings.ToLower(b.GetName())
+		}
+	case "id":
+		return func(a, b *pbDishes.Dish) bool { return a.GetId() < b.GetId() }
+	default:
+		key := dishSortKeys[q.sortKey]
+		return func(a, b *pbDishes.Dish) bool { return key(a) < key(b) }
+	}
+}
+
+func parseIDSet(raw, param string) (map[int32]bool, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	ids := make(map[int32]bool)
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", param, part)
+		}
+		ids[int32(id)] = true
+	}
+	return ids, nil
+}
+
+func parseOptionalFloat(c *gin.Context, param string) (*float64, error) {
+	raw, ok := c.GetQuery(param)
+	if !ok {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q", param, raw)
+	}
+	return &v, nil
+}
+
+var (
+	errInvalidCursor  = errors.New("invalid cursor")
+	errCursorMismatch = errors.New("cursor was issued for different filters or sort order")
+)
+
+// Курсор - смещение и отпечаток запроса, для которого он выдан.
+func encodeCursor(offset int, fingerprint string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset) + ":" + fingerprint))
+}
+
+func decodeCursor(cursor, fingerprint string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	rawOffset, cursorFingerprint, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, errInvalidCursor
+	}
+	offset, err := strconv.Atoi(rawOffset)
+	if err != nil || offset < 0 {
+		return 0, errInvalidCursor
+	}
+	if cursorFingerprint != fingerprint {
+		return 0, errCursorMismatch
+	}
+	return offset, nil
+}
@@ -0,0 +1,296 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/api"
+	"github.com/anyviewww/bff-service/internal/auth"
+	"github.com/anyviewww/bff-service/internal/cart"
+	"github.com/anyviewww/bff-service/internal/client"
+	"github.com/anyviewww/bff-service/internal/health"
+	"github.com/anyviewww/bff-service/internal/idempotency"
+	"github.com/anyviewww/bff-service/internal/logging"
+	"github.com/anyviewww/bff-service/internal/pricing"
+	"github.com/anyviewww/bff-service/internal/ratelimit"
+	"github.com/anyviewww/bff-service/internal/testing/fakes"
+	"github.com/anyviewww/bff-service/internal/watch"
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+)
+
+const (
+	testJWTSecret = "integration-test-secret"
+	testAdminRole = "admin"
+	// Дедлайн вызова бэкенда, после которого срабатывают тесты задержек
+	testCallTimeout = 300 * time.Millisecond
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+type envConfig struct {
+	auth      bool
+	menuCache bool
+	// anonymousAdmin открывает административные эндпоинты без аутентификации
+	anonymousAdmin bool
+	rateLimit      *api.RateLimitOptions
+	graphQL        api.GraphQLOptions
+}
+
+// testEnv - BFF целиком, от маршрутов gin до gRPC-клиентов, поверх
+// бэкендов из пакета fakes.
+type testEnv struct {
+	t       *testing.T
+	backend *fakes.Server
+	engine  *gin.Engine
+}
+
+func newTestEnv(t *testing.T, cfg envConfig) *testEnv {
+	t.Helper()
+
+	backend := fakes.NewServer()
+	t.Cleanup(backend.Close)
+
+	callOpts := client.CallOptions{
+		Timeout:      testCallTimeout,
+		MaxRetries:   1,
+		RetryBackoff: 10 * time.Millisecond,
+	}
+	dial := func(name string) *grpc.ClientConn {
+		opts := callOpts
+		opts.Interceptors = []grpc.UnaryClientInterceptor{logging.UnaryClientInterceptor(name)}
+		opts.StreamInterceptors = []grpc.StreamClientInterceptor{logging.StreamClientInterceptor(name)}
+		conn, err := client.Dial("bufconn", nil, opts, nil, backend.DialOption())
+		if err != nil {
+			t.Fatalf("dial %s: %v", name, err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	menuConn := dial("menu")
+	orderConn := dial("order")
+
+	menuClient := client.NewMenuClient(menuConn)
+	orderClient := client.NewOrderClient(orderConn)
+
+	calculator, err := pricing.NewCalculator(pricing.Options{
+		Currency:         "RUB",
+		TaxRate:          "20",
+		CategoryTaxRates: map[int32]string{3: "10"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := watch.NewWatcher(orderClient)
+	t.Cleanup(watcher.Close)
+
+	opts := []api.Option{
+		api.WithIdempotencyStore(idempotency.NewMemoryStore(time.Hour, time.Minute)),
+		api.WithCartStore(cart.NewMemoryStore(time.Hour)),
+		api.WithPricing(calculator),
+		api.WithOrderEvents(watcher, api.OrderEventsOptions{Heartbeat: time.Second}),
+		api.WithReadiness(health.NewChecker(time.Second, true,
+			health.Dependency{Name: "menu", Conn: menuConn},
+			health.Dependency{Name: "order", Conn: orderConn},
+		)),
+		api.WithGraphQL(cfg.graphQL),
+	}
+
+	var dishService pbDishes.DishServiceClient = menuClient
+	if cfg.menuCache {
+		menuCache := client.NewMenuCache(menuClient, time.Minute, time.Minute)
+		dishService = menuCache
+		opts = append(opts, api.WithMenuCache(menuCache))
+	}
+	if cfg.auth {
+		verifier, err := auth.NewVerifier(auth.Options{HMACSecret: testJWTSecret, AdminRole: testAdminRole})
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, api.WithAuth(verifier))
+	}
+	if cfg.anonymousAdmin {
+		opts = append(opts, api.WithUnauthenticatedAdmin())
+	}
+	if cfg.rateLimit != nil {
+		opts = append(opts, api.WithRateLimit(ratelimit.NewMemoryStore(), *cfg.rateLimit))
+	}
+
+	engine := gin.New()
+	// Как в сервере без server.trusted_proxies: X-Forwarded-For не меняет
+	// адрес клиента
+	if err := engine.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	engine.Use(logging.RequestID(), gin.Recovery())
+	api.NewRouter(api.NewHandler(dishService, orderClient, opts...)).SetupRoutes(engine)
+
+	return &testEnv{t: t, backend: backend, engine: engine}
+}
+
+// do выполняет запрос к BFF. headers - пары имя, значение.
+func (e *testEnv) do(method, path string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			e.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	e.engine.ServeHTTP(rec, req)
+	return rec
+}
+
+// serve запускает BFF на реальном порту для потоковых ответов.
+func (e *testEnv) serve() *httptest.Server {
+	srv := httptest.NewServer(e.engine)
+	e.t.Cleanup(srv.Close)
+	return srv
+}
+
+// expectStatus проверяет код ответа и возвращает разобранное тело.
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) map[string]interface{} {
+	t.Helper()
+
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, want, rec.Body.String())
+	}
+	if rec.Body.Len() == 0 {
+		return nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+// expectError проверяет ответ с ошибкой в формате API.
+func expectError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) map[string]interface{} {
+	t.Helper()
+
+	body := expectStatus(t, rec, status)
+	apiErr, ok := body["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no error object: %s", rec.Body.String())
+	}
+	if apiErr["code"] != code {
+		t.Fatalf("error code = %v, want %s; body: %s", apiErr["code"], code, rec.Body.String())
+	}
+	return apiErr
+}
+
+func bearer(t *testing.T, userID uint64, roles ...string) string {
+	t.Helper()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: roles,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func ids(t *testing.T, list interface{}) []float64 {
+	t.Helper()
+
+	items, ok := list.([]interface{})
+	if !ok {
+		t.Fatalf("expected list, got %T", list)
+	}
+	result := make([]float64, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.(map[string]interface{})["id"].(float64))
+	}
+	return result
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	data  map[string]interface{}
+}
+
+// readSSE читает следующее событие, пропуская комментарии и retry.
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if ev.event != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev.data); err != nil {
+				t.Fatalf("decode event data %q: %v", line, err)
+			}
+		}
+	}
+}
+
+func openSSE(t *testing.T, url string, headers ...string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/anyviewww/bff-service/internal/logging"
+	"github.com/anyviewww/bff-service/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// statusClientClosedRequest - нестандартный код nginx для запросов,
+// отменённых клиентом до получения ответа.
+const statusClientClosedRequest = 499
+
+// Сообщение, которое отдаётся клиенту вместо внутренних ошибок бэкендов
+const internalErrorMessage = "Internal server error"
+
+type errorMapping struct {
+	httpStatus int
+	name       string
+}
+
+var grpcErrorMappings = map[codes.Code]errorMapping{
+	codes.Canceled:           {statusClientClosedRequest, "CANCELLED"},
+	codes.Unknown:            {http.StatusInternalServerError, "UNKNOWN"},
+	codes.InvalidArgument:    {http.StatusBadRequest, "INVALID_ARGUMENT"},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, "DEADLINE_EXCEEDED"},
+	codes.NotFound:           {http.StatusNotFound, "NOT_FOUND"},
+	codes.AlreadyExists:      {http.StatusConflict, "ALREADY_EXISTS"},
+	codes.PermissionDenied:   {http.StatusForbidden, "PERMISSION_DENIED"},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, "RESOURCE_EXHAUSTED"},
+	codes.FailedPrecondition: {http.StatusBadRequest, "FAILED_PRECONDITION"},
+	codes.Aborted:            {http.StatusConflict, "ABORTED"},
+	codes.OutOfRange:         {http.StatusBadRequest, "OUT_OF_RANGE"},
+	codes.Unimplemented:      {http.StatusNotImplemented, "UNIMPLEMENTED"},
+	codes.Internal:           {http.StatusInternalServerError, "INTERNAL"},
+	codes.Unavailable:        {http.StatusServiceUnavailable, "UNAVAILABLE"},
+	codes.DataLoss:           {http.StatusInternalServerError, "DATA_LOSS"},
+	codes.Unauthenticated:    {http.StatusUnauthorized, "UNAUTHENTICATED"},
+}
+
+func mappingFor(code codes.Code) errorMapping {
+	if m, ok := grpcErrorMappings[code]; ok {
+		return m
+	}
+	return grpcErrorMappings[codes.Unknown]
+}
+
+// hidesMessage сообщает, что текст ошибки с таким кодом не должен попадать
+// к клиенту: он может содержать внутренние детали бэкенда.
+func hidesMessage(code codes.Code) bool {
+	return code == codes.Internal || code == codes.Unknown || code == codes.DataLoss
+}
+
+// respondError отправляет ошибку в едином формате. Код задаётся в терминах
+// gRPC, чтобы локальные ошибки и ошибки бэкендов выглядели одинаково.
+func respondError(c *gin.Context, code codes.Code, message string) {
+	writeError(c, mappingFor(code), message, nil)
+}
+
+// respondHTTPError отправляет ошибку, у которой нет подходящего кода gRPC,
+// например 422 Unprocessable Entity.
+func respondHTTPError(c *gin.Context, httpStatus int, name, message string) {
+	writeError(c, errorMapping{httpStatus: httpStatus, name: name}, message, nil)
+}
+
+// respondValidationError отправляет ошибки проверки запроса списком полей.
+func respondValidationError(c *gin.Context, fields []openapi.FieldError) {
+	body := errorResponse(c, mappingFor(codes.InvalidArgument), "Request validation failed", nil)
+	body.Error.Fields = fields
+	c.AbortWithStatusJSON(http.StatusBadRequest, body)
+}
+
+// respondGRPCError переводит ошибку вызова бэкенда в HTTP-ответ.
+func respondGRPCError(c *gin.Context, err error) {
+	st := grpcStatus(err)
+
+	message := st.Message()
+	var details []ErrorDetail
+	if hidesMessage(st.Code()) {
+		slog.ErrorContext(c.Request.Context(), "backend error",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.Any("error", err),
+		)
+		message = internalErrorMessage
+	} else {
+		details = statusDetails(st)
+	}
+
+	writeError(c, mappingFor(st.Code()), message, details)
+}
+
+// publicErrorMessage возвращает текст ошибки, который безопасно отдавать
+// клиенту, например в маркерах частичных ошибок.
+func publicErrorMessage(err error) string {
+	st := grpcStatus(err)
+	if hidesMessage(st.Code()) {
+		return internalErrorMessage
+	}
+	return st.Message()
+}
+
+func grpcStatus(err error) *status.Status {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+	return status.Convert(err)
+}
+
+func writeError(c *gin.Context, m errorMapping, message string, details []ErrorDetail) {
+	c.AbortWithStatusJSON(m.httpStatus, errorResponse(c, m, message, details))
+}
+
+func errorResponse(c *gin.Context, m errorMapping, message string, details []ErrorDetail) ErrorResponse {
+	return ErrorResponse{Error: ErrorBody{
+		Code:      m.name,
+		Message:   message,
+		RequestID: requestID(c),
+		Details:   details,
+	}}
+}
+
+func requestID(c *gin.Context) string {
+	return logging.RequestIDFromContext(c.Request.Context())
+}
+
+func statusDetails(st *status.Status) []ErrorDetail {
+	var details []ErrorDetail
+	for _, d := range st.Details() {
+		msg, ok := d.(proto.Message)
+		if !ok {
+			continue
+		}
+		raw, err := protojson.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		details = append(details, ErrorDetail{
+			Type:  string(msg.ProtoReflect().Descriptor().FullName()),
+			Value: json.RawMessage(raw),
+		})
+	}
+	return details
+}
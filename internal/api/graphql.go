@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/anyviewww/bff-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"google.golang.org/grpc/codes"
+)
+
+type GraphQLOptions struct {
+	// MaxDepth - максимальная вложенность полей, 0 - без ограничения
+	MaxDepth int
+	// MaxComplexity - максимальная оценка числа полей в ответе, 0 - без
+	// ограничения
+	MaxComplexity int
+}
+
+type graphQLServer struct {
+	schema graphql.Schema
+	opts   GraphQLOptions
+}
+
+// WithGraphQL включает эндпоинт /graphql.
+func WithGraphQL(opts GraphQLOptions) Option {
+	return func(h *Handler) {
+		schema, err := h.graphQLSchema()
+		if err != nil {
+			// Схема задаётся в коде, ошибка в ней - ошибка программиста
+			panic(fmt.Sprintf("api: invalid GraphQL schema: %v", err))
+		}
+		h.graphql = &graphQLServer{schema: schema, opts: opts}
+	}
+}
+
+// GraphQLRequest - тело POST /graphql.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required" openapi:"minLength=1"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type GraphQLResponse struct {
+	// Data отсутствует, если запрос не дошёл до выполнения
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message   string            `json:"message"`
+	Locations []GraphQLLocation `json:"locations,omitempty"`
+	Path      []interface{}     `json:"path,omitempty"`
+	// Extensions.code - код ошибки в том же виде, что error.code в REST API
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQL выполняет запрос GraphQL. Ошибки разбора, проверки и лимитов
+// возвращаются с кодом 400, ошибки резолверов - в errors ответа 200 вместе
+// с частичными данными. По GET разрешены только запросы без мутаций.
+func (h *Handler) GraphQL(c *gin.Context) {
+	if h.graphql == nil {
+		respondError(c, codes.Unimplemented, "GraphQL is not enabled")
+		return
+	}
+
+	req, ok := graphQLRequest(c)
+	if !ok {
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		respondGraphQL(c, http.StatusBadRequest, nil, gqlerrors.FormatErrors(err))
+		return
+	}
+	if result := graphql.ValidateDocument(&h.graphql.schema, doc, nil); !result.IsValid {
+		respondGraphQL(c, http.StatusBadRequest, nil, result.Errors)
+		return
+	}
+
+	op := findOperation(doc, req.OperationName)
+	if op == nil {
+		message := "Must provide operation name if query contains multiple operations"
+		if req.OperationName != "" {
+			message = fmt.Sprintf("Unknown operation named %q", req.OperationName)
+		}
+		respondGraphQL(c, http.StatusBadRequest, nil, requestErrors(newGraphQLError(codes.InvalidArgument, message)))
+		return
+	}
+	if c.Request.Method != http.MethodPost && op.Operation != ast.OperationTypeQuery {
+		c.Header("Allow", http.MethodPost)
+		respondGraphQL(c, http.StatusMethodNotAllowed, nil, requestErrors(newGraphQLError(codes.InvalidArgument,
+			fmt.Sprintf("Only queries are allowed over %s, use POST for %s", c.Request.Method, op.Operation))))
+		return
+	}
+
+	cost := analyzeQuery(&h.graphql.schema, doc, op, req.Variables)
+	if err := checkQueryLimits(cost, h.graphql.opts); err != nil {
+		respondGraphQL(c, http.StatusBadRequest, nil, requestErrors(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	state := &graphQLState{dishes: newDishLoader(ctx, h.menuClient)}
+	state.principal, _ = principal(c)
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.graphql.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, graphQLStateKey{}, state),
+	})
+	respondGraphQL(c, http.StatusOK, result.Data, result.Errors)
+}
+
+// graphQLRequest читает запрос из тела POST или параметров GET. При ошибке
+// ответ уже отправлен и возвращается false.
+func graphQLRequest(c *gin.Context) (GraphQLRequest, bool) {
+	var req GraphQLRequest
+	if c.Request.Method == http.MethodPost {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, codes.InvalidArgument, err.Error())
+			return req, false
+		}
+		return req, true
+	}
+
+	req.Query = c.Query("query")
+	req.OperationName = c.Query("operationName")
+	if raw := c.Query("variables"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+			respondError(c, codes.InvalidArgument, "variables must be a JSON object")
+			return req, false
+		}
+	}
+	if req.Query == "" {
+		respondError(c, codes.InvalidArgument, "query is required")
+		return req, false
+	}
+	return req, true
+}
+
+func respondGraphQL(c *gin.Context, httpStatus int, data interface{}, errs []gqlerrors.FormattedError) {
+	resp := GraphQLResponse{Data: data}
+	for _, err := range errs {
+		gqlErr := GraphQLError{
+			Message:    err.Message,
+			Path:       err.Path,
+			Extensions: err.Extensions,
+		}
+		if gqlErr.Extensions == nil {
+			gqlErr.Extensions = errorExtensions(err.OriginalError())
+		}
+		for _, loc := range err.Locations {
+			gqlErr.Locations = append(gqlErr.Locations, GraphQLLocation{Line: loc.Line, Column: loc.Column})
+		}
+		resp.Errors = append(resp.Errors, gqlErr)
+	}
+	c.JSON(httpStatus, resp)
+}
+
+// errorExtensions ищет extensions в цепочке исходных ошибок. Исполнитель
+// оформляет ошибки отложенных значений дважды и при этом теряет extensions.
+func errorExtensions(err error) map[string]interface{} {
+	for err != nil {
+		switch e := err.(type) {
+		case gqlerrors.ExtendedError:
+			return e.Extensions()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		case gqlerrors.FormattedError:
+			if e.Extensions != nil {
+				return e.Extensions
+			}
+			err = e.OriginalError()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// requestErrors оформляет ошибку, найденную до выполнения запроса. Ошибки
+// резолверов оформляет исполнитель, в том числе добавляет extensions.
+func requestErrors(err error) []gqlerrors.FormattedError {
+	return []gqlerrors.FormattedError{gqlerrors.FormatError(&gqlerrors.Error{
+		Message:       err.Error(),
+		OriginalError: err,
+	})}
+}
+
+// graphQLState - данные одного запроса GraphQL, доступные резолверам через
+// контекст.
+type graphQLState struct {
+	principal *auth.Principal
+	dishes    *dishLoader
+}
+
+type graphQLStateKey struct{}
+
+func graphQLStateFrom(ctx context.Context) *graphQLState {
+	state, _ := ctx.Value(graphQLStateKey{}).(*graphQLState)
+	return state
+}
+
+// graphQLError - ошибка резолвера с кодом в extensions.
+type graphQLError struct {
+	code    codes.Code
+	message string
+}
+
+func newGraphQLError(code codes.Code, message string) error {
+	return &graphQLError{code: code, message: message}
+}
+
+func (e *graphQLError) Error() string { return e.message }
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": mappingFor(e.code).name}
+}
+
+// backendGraphQLError переводит ошибку вызова бэкенда в ошибку резолвера,
+// скрывая внутренние детали так же, как respondGRPCError.
+func backendGraphQLError(ctx context.Context, err error) error {
+	st := grpcStatus(err)
+	message := st.Message()
+	if hidesMessage(st.Code()) {
+		slog.ErrorContext(ctx, "backend error",
+			slog.String("route", "/graphql"),
+			slog.Any("error", err),
+		)
+		message = internalErrorMessage
+	}
+	return &graphQLError{code: st.Code(), message: message}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Размер списка для оценки сложности, если он не задан аргументом
+const defaultListComplexity = 10
+
+// listSizeHints задаёт ожидаемый размер списков, которые возвращают поля,
+// по аргументам поля. Для остальных полей-списков используется
+// defaultListComplexity.
+var listSizeHints = map[string]func(args map[string]interface{}) int{
+	"Query.dishes": func(args map[string]interface{}) int {
+		if ids, ok := args["ids"].([]interface{}); ok {
+			return len(ids)
+		}
+		return maxDishesLimit
+	},
+	"Query.orders": func(args map[string]interface{}) int {
+		if size, ok := args["pageSize"].(int); ok {
+			// Недопустимый размер отклонит резолвер, здесь важно только,
+			// чтобы оценка не стала отрицательной или не переполнилась
+			return min(max(size, 0), maxOrdersPageSize)
+		}
+		return defaultOrdersPageSize
+	},
+	// Размер страницы уже учтён в Query.orders
+	"OrderConnection.orders": func(map[string]interface{}) int { return 1 },
+}
+
+// queryCost - глубина и сложность операции. Сложность - число полей с
+// учётом размера списков: поле стоит 1 плюс стоимость вложенных полей,
+// умноженная на ожидаемое число элементов. Служебные поля __schema,
+// __type и __typename не учитываются, чтобы не мешать интроспекции.
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+type costAnalyzer struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// visiting защищает от циклов фрагментов, если проверка документа их
+	// пропустила
+	visiting map[string]bool
+}
+
+// findOperation возвращает операцию, которую выполнит запрос, или nil, если
+// её нельзя однозначно определить: об этом сообщит исполнитель.
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
+
+func analyzeQuery(schema *graphql.Schema, doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}) queryCost {
+	a := &costAnalyzer{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			a.fragments[frag.Name.Value] = frag
+		}
+	}
+
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeMutation:
+		root = schema.MutationType()
+	default:
+		root = schema.QueryType()
+	}
+	return a.selectionSet(op.SelectionSet, root, 0)
+}
+
+func (a *costAnalyzer) selectionSet(set *ast.SelectionSet, parent graphql.Type, depth int) queryCost {
+	var total queryCost
+	if set == nil {
+		return total
+	}
+
+	add := func(c queryCost) {
+		total.complexity += c.complexity
+		if c.depth > total.depth {
+			total.depth = c.depth
+		}
+	}
+
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			add(a.field(s, parent, depth))
+		case *ast.InlineFragment:
+			typ := parent
+			if s.TypeCondition != nil {
+				typ = a.schema.Type(s.TypeCondition.Name.Value)
+			}
+			add(a.selectionSet(s.SelectionSet, typ, depth))
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			frag, ok := a.fragments[name]
+			if !ok || a.visiting[name] {
+				continue
+			}
+			a.visiting[name] = true
+			add(a.selectionSet(frag.SelectionSet, a.schema.Type(frag.TypeCondition.Name.Value), depth))
+			delete(a.visiting, name)
+		}
+	}
+	return total
+}
+
+func (a *costAnalyzer) field(f *ast.Field, parent graphql.Type, depth int) queryCost {
+	name := f.Name.Value
+	if parent == nil || strings.HasPrefix(name, "__") {
+		return queryCost{}
+	}
+
+	var fields graphql.FieldDefinitionMap
+	switch t := parent.(type) {
+	case *graphql.Object:
+		fields = t.Fields()
+	case *graphql.Interface:
+		fields = t.Fields()
+	}
+	def, ok := fields[name]
+	if !ok {
+		return queryCost{}
+	}
+
+	fieldType, _ := graphql.GetNamed(def.Type).(graphql.Type)
+	children := a.selectionSet(f.SelectionSet, fieldType, depth+1)
+
+	multiplier := 1
+	if hint, ok := listSizeHints[parent.Name()+"."+name]; ok {
+		multiplier = hint(a.arguments(f))
+	} else if isList(def.Type) {
+		multiplier = defaultListComplexity
+	}
+
+	return queryCost{
+		depth:      max(depth+1, children.depth),
+		complexity: 1 + multiplier*children.complexity,
+	}
+}
+
+// arguments вычисляет аргументы поля, заданные литералами или
+// переменными. Сложные значения, кроме списков, не нужны для оценки.
+func (a *costAnalyzer) arguments(f *ast.Field) map[string]interface{} {
+	args := make(map[string]interface{}, len(f.Arguments))
+	for _, arg := range f.Arguments {
+		args[arg.Name.Value] = a.value(arg.Value)
+	}
+	return args
+}
+
+func (a *costAnalyzer) value(v ast.Value) interface{} {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return nil
+		}
+		return n
+	case *ast.ListValue:
+		values := make([]interface{}, 0, len(v.Values))
+		for _, item := range v.Values {
+			values = append(values, a.value(item))
+		}
+		return values
+	case *ast.Variable:
+		switch value := a.variables[v.Name.Value].(type) {
+		case float64:
+			return int(value)
+		default:
+			return value
+		}
+	}
+	return nil
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+// limitError - ошибка превышения лимитов запроса.
+type limitError struct {
+	code    string
+	message string
+}
+
+func (e *limitError) Error() string { return e.message }
+
+func (e *limitError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func checkQueryLimits(cost queryCost, opts GraphQLOptions) error {
+	if opts.MaxDepth > 0 && cost.depth > opts.MaxDepth {
+		return &limitError{"QUERY_TOO_DEEP", fmt.Sprintf("query depth %d exceeds the limit of %d", cost.depth, opts.MaxDepth)}
+	}
+	if opts.MaxComplexity > 0 && cost.complexity > opts.MaxComplexity {
+		return &limitError{"QUERY_TOO_COMPLEX", fmt.Sprintf("query complexity %d exceeds the limit of %d", cost.complexity, opts.MaxComplexity)}
+	}
+	return nil
+}
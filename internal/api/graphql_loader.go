@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dishLoader собирает запросы блюд в пределах одного запроса GraphQL и
+// выполняет их пачкой. Резолвер вызывает Load и возвращает исполнителю
+// отложенное значение; когда исполнитель запрашивает первое из них, все
+// накопленные к этому моменту id загружаются одним вызовом DishService.
+type dishLoader struct {
+	ctx    context.Context
+	client pbDishes.DishServiceClient
+
+	mu      sync.Mutex
+	pending []int64
+	results map[int64]dishResult
+}
+
+type dishResult struct {
+	dish *pbDishes.Dish
+	err  error
+}
+
+func newDishLoader(ctx context.Context, client pbDishes.DishServiceClient) *dishLoader {
+	return &dishLoader{
+		ctx:     ctx,
+		client:  client,
+		results: make(map[int64]dishResult),
+	}
+}
+
+// Load регистрирует блюдо для следующей пачки. Отсутствующее блюдо
+// возвращается ошибкой с кодом NotFound.
+func (l *dishLoader) Load(id int64) func() (*pbDishes.Dish, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok && !containsDishID(l.pending, id) {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (*pbDishes.Dish, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.results[id]; !ok {
+			l.dispatch()
+		}
+		r := l.results[id]
+		return r.dish, r.err
+	}
+}
+
+// Prime добавляет уже полученные блюда, чтобы они не запрашивались повторно.
+func (l *dishLoader) Prime(dishes []*pbDishes.Dish) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, dish := range dishes {
+		l.results[int64(dish.GetId())] = dishResult{dish: dish}
+	}
+}
+
+// dispatch загружает накопленные id, вызывается под l.mu. DishService не
+// умеет отдавать несколько блюд по списку id, поэтому одно блюдо
+// запрашивается по id, а пачка - одним запросом всего меню.
+func (l *dishLoader) dispatch() {
+	ids := l.pending
+	l.pending = nil
+
+	valid := ids[:0:0]
+	for _, id := range ids {
+		if id < math.MinInt32 || id > math.MaxInt32 {
+			l.results[id] = dishResult{err: status.Error(codes.InvalidArgument, "Invalid dish ID")}
+			continue
+		}
+		valid = append(valid, id)
+	}
+	if len(valid) == 0 {
+		return
+	}
+
+	req := &pbDishes.DishRequest{}
+	if len(valid) == 1 {
+		req.Id = int32(valid[0])
+	}
+	resp, err := l.client.GetDishes(l.ctx, req)
+	if err != nil {
+		for _, id := range valid {
+			l.results[id] = dishResult{err: err}
+		}
+		return
+	}
+
+	found := make(map[int64]*pbDishes.Dish, len(resp.Dishes))
+	for _, dish := range resp.Dishes {
+		found[int64(dish.GetId())] = dish
+	}
+	for _, id := range valid {
+		if dish, ok := found[id]; ok {
+			l.results[id] = dishResult{dish: dish}
+		} else {
+			l.results[id] = dishResult{err: status.Error(codes.NotFound, "Dish not found")}
+		}
+	}
+}
@@ -0,0 +1,561 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/anyviewww/bff-service/internal/order"
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"github.com/graphql-go/graphql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// graphQLSchema строит схему GraphQL. Резолверы повторяют логику REST
+// API: те же проверки доступа к заказам и позициям, те же коды ошибок в
+// extensions.code. Поля объектов берутся из типов ответов REST API.
+func (h *Handler) graphQLSchema() (graphql.Schema, error) {
+	named := func(name, description string) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        name,
+			Description: description,
+			Fields: graphql.Fields{
+				"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			},
+		})
+	}
+
+	nutritionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "NutritionFact",
+		Description: "Пищевая ценность",
+		Fields: graphql.Fields{
+			"calories":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"proteins":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"fats":          &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"carbohydrates": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	moneyType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Money",
+		Fields: graphql.Fields{
+			// Сумма в минимальных единицах не помещается в Int GraphQL
+			"amountMinor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return strconv.FormatInt(p.Source.(*MoneyResponse).AmountMinor, 10), nil
+				},
+			},
+			"amount":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"currency": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	dishType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Dish",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"type":     &graphql.Field{Type: graphql.NewNonNull(named("Type", "Тип блюда"))},
+			"category": &graphql.Field{Type: graphql.NewNonNull(named("Category", "Категория блюда"))},
+			"tag":      &graphql.Field{Type: graphql.NewNonNull(named("Tag", "Тег блюда"))},
+			"nutrition": &graphql.Field{
+				Type: graphql.NewNonNull(nutritionType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(DishResponse).Nutrition
+					return NutritionTotalsResponse{
+						Calories:      decimalFloat(n.Calories),
+						Proteins:      decimalFloat(n.Proteins),
+						Fats:          decimalFloat(n.Fats),
+						Carbohydrates: decimalFloat(n.Carbohydrates),
+					}, nil
+				},
+			},
+			"recipe": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"price": &graphql.Field{
+				Type:        moneyType,
+				Description: "Цена блюда, null, если она не задана",
+			},
+		},
+	})
+
+	orderItemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderItem",
+		Fields: graphql.Fields{
+			"dishId":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"notes":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"modifiers": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"dish": &graphql.Field{
+				Type:        dishType,
+				Description: "Блюдо из DishService, null с ошибкой в errors, если его не удалось получить",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := graphQLStateFrom(p.Context).dishes.Load(p.Source.(OrderItemResponse).DishID)
+					return func() (interface{}, error) {
+						dish, err := load()
+						if err != nil {
+							return nil, backendGraphQLError(p.Context, err)
+						}
+						return h.toDishResponse(dish), nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	orderType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return strconv.FormatUint(p.Source.(OrderResponse).ID, 10), nil
+				},
+			},
+			"userId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return strconv.FormatUint(p.Source.(OrderResponse).UserID, 10), nil
+				},
+			},
+			"status": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"items": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderItemType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(OrderResponse).OrderItems, nil
+				},
+			},
+			"nutritionTotals": &graphql.Field{
+				Type:        nutritionType,
+				Description: "Суммарная пищевая ценность с учётом количества, null, если не удалось получить хотя бы одно блюдо",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nutritionTotals(p.Context, p.Source.(OrderResponse).OrderItems), nil
+				},
+			},
+		},
+	})
+
+	orderConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderConnection",
+		Fields: graphql.Fields{
+			"orders":        &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderType)))},
+			"nextPageToken": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	orderItemInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "OrderItemInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"dishId":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity":  &graphql.InputObjectFieldConfig{Type: graphql.Int, DefaultValue: 1},
+			"notes":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"modifiers": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+	orderItemsInput := graphql.NewList(graphql.NewNonNull(orderItemInput))
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"dish": &graphql.Field{
+				Type: dishType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: h.resolveDish,
+			},
+			"dishes": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dishType))),
+				Description: "Блюда по списку id или всё меню. Отсутствующие блюда пропускаются",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+				},
+				Resolve: h.resolveDishes,
+			},
+			"order": &graphql.Field{
+				Type: orderType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveOrder,
+			},
+			"orders": &graphql.Field{
+				Type:        graphql.NewNonNull(orderConnectionType),
+				Description: "Заказы пользователя, по умолчанию - текущего",
+				Args: graphql.FieldConfigArgument{
+					"userId":    &graphql.ArgumentConfig{Type: graphql.ID},
+					"status":    &graphql.ArgumentConfig{Type: graphql.String},
+					"pageSize":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultOrdersPageSize},
+					"pageToken": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: h.resolveOrders,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createOrder": &graphql.Field{
+				Type: graphql.NewNonNull(orderType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+						Name: "CreateOrderInput",
+						Fields: graphql.InputObjectConfigFieldMap{
+							"userId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+							"items":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(orderItemsInput)},
+						},
+					}))},
+				},
+				Resolve: h.resolveCreateOrder,
+			},
+			"updateOrder": &graphql.Field{
+				Type: graphql.NewNonNull(orderType),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewInputObject(graphql.InputObjectConfig{
+						Name:        "UpdateOrderInput",
+						Description: "Изменяются только переданные поля",
+						Fields: graphql.InputObjectConfigFieldMap{
+							"userId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+							"items":  &graphql.InputObjectFieldConfig{Type: orderItemsInput},
+							"status": &graphql.InputObjectFieldConfig{Type: graphql.String},
+						},
+					}))},
+				},
+				Resolve: h.resolveUpdateOrder,
+			},
+			"deleteOrder": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveDeleteOrder,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// decimalFloat переводит float32 в float64 по десятичной записи, чтобы
+// 0.3 не превращалось в 0.30000001192092896.
+func decimalFloat(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+	return f
+}
+
+// nutritionTotals возвращает отложенную сумму пищевой ценности: блюда
+// всех заказов ответа загружаются одной пачкой.
+func nutritionTotals(ctx context.Context, items []OrderItemResponse) func() (interface{}, error) {
+	loader := graphQLStateFrom(ctx).dishes
+	loads := make([]func() (*pbDishes.Dish, error), 0, len(items))
+	for _, item := range items {
+		loads = append(loads, loader.Load(item.DishID))
+	}
+
+	return func() (interface{}, error) {
+		var totals NutritionTotalsResponse
+		for i, load := range loads {
+			dish, err := load()
+			if err != nil {
+				// Ошибка по блюду уже есть в OrderItem.dish, если оно запрошено
+				return nil, nil
+			}
+			qty := float64(items[i].Quantity)
+			nf := dish.GetNutFact()
+			totals.Calories += decimalFloat(nf.GetCalories()) * qty
+			totals.Proteins += decimalFloat(nf.GetProteins()) * qty
+			totals.Fats += decimalFloat(nf.GetFats()) * qty
+			totals.Carbohydrates += decimalFloat(nf.GetCarbohydrates()) * qty
+		}
+		return totals, nil
+	}
+}
+
+func (h *Handler) resolveDish(p graphql.ResolveParams) (interface{}, error) {
+	load := graphQLStateFrom(p.Context).dishes.Load(int64(p.Args["id"].(int)))
+	return func() (interface{}, error) {
+		dish, err := load()
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, backendGraphQLError(p.Context, err)
+		}
+		return h.toDishResponse(dish), nil
+	}, nil
+}
+
+func (h *Handler) resolveDishes(p graphql.ResolveParams) (interface{}, error) {
+	loader := graphQLStateFrom(p.Context).dishes
+
+	ids, ok := p.Args["ids"].([]interface{})
+	if !ok {
+		resp, err := h.menuClient.GetDishes(p.Context, &pbDishes.DishRequest{})
+		if err != nil {
+			return nil, backendGraphQLError(p.Context, err)
+		}
+		loader.Prime(resp.Dishes)
+
+		dishes := make([]DishResponse, 0, len(resp.Dishes))
+		for _, dish := range resp.Dishes {
+			dishes = append(dishes, h.toDishResponse(dish))
+		}
+		return dishes, nil
+	}
+
+	loads := make([]func() (*pbDishes.Dish, error), 0, len(ids))
+	for _, id := range ids {
+		loads = append(loads, loader.Load(int64(id.(int))))
+	}
+	return func() (interface{}, error) {
+		dishes := make([]DishResponse, 0, len(loads))
+		for _, load := range loads {
+			dish, err := load()
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			if err != nil {
+				return nil, backendGraphQLError(p.Context, err)
+			}
+			dishes = append(dishes, h.toDishResponse(dish))
+		}
+		return dishes, nil
+	}, nil
+}
+
+func (h *Handler) resolveOrder(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseGraphQLID(p.Args["id"], "Invalid order ID format")
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := h.ownedOrder(p.Context, id)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toOrderResponse(o), nil
+}
+
+func (h *Handler) resolveOrders(p graphql.ResolveParams) (interface{}, error) {
+	state := graphQLStateFrom(p.Context)
+
+	var userID uint64
+	if raw, ok := p.Args["userId"]; ok {
+		id, err := parseGraphQLID(raw, "Invalid user ID format")
+		if err != nil {
+			return nil, err
+		}
+		userID = id
+	} else if state.principal != nil {
+		userID = state.principal.UserID
+	} else {
+		return nil, newGraphQLError(codes.InvalidArgument, "userId is required")
+	}
+	if !principalCanAccess(state.principal, userID) {
+		return nil, newGraphQLError(codes.PermissionDenied, "Access to this user's orders is denied")
+	}
+
+	pageSize := p.Args["pageSize"].(int)
+	if pageSize < 1 || pageSize > maxOrdersPageSize {
+		return nil, newGraphQLError(codes.InvalidArgument, "Invalid pageSize value")
+	}
+	orderStatus, _ := p.Args["status"].(string)
+	pageToken, _ := p.Args["pageToken"].(string)
+
+	resp, err := h.orderClient.ListOrders(p.Context, &pbOrders.ListOrdersRequest{
+		UserId:    userID,
+		Status:    orderStatus,
+		PageSize:  int32(pageSize),
+		PageToken: pageToken,
+	})
+	if err != nil {
+		return nil, backendGraphQLError(p.Context, err)
+	}
+
+	orders := make([]OrderResponse, 0, len(resp.Orders))
+	for _, o := range resp.Orders {
+		orders = append(orders, toOrderResponse(o))
+	}
+	result := map[string]interface{}{"orders": orders, "nextPageToken": nil}
+	if resp.NextPageToken != "" {
+		result["nextPageToken"] = resp.NextPageToken
+	}
+	return result, nil
+}
+
+func (h *Handler) resolveCreateOrder(p graphql.ResolveParams) (interface{}, error) {
+	state := graphQLStateFrom(p.Context)
+	input := p.Args["input"].(map[string]interface{})
+
+	orderItems, items, err := graphQLOrderItems(input["items"])
+	if err != nil {
+		return nil, err
+	}
+	if len(orderItems) == 0 {
+		return nil, newGraphQLError(codes.InvalidArgument, "items is required")
+	}
+
+	var userID uint64
+	if raw, ok := input["userId"]; ok {
+		if userID, err = parseGraphQLID(raw, "Invalid user ID format"); err != nil {
+			return nil, err
+		}
+	}
+	// Как и в REST API, указать другого пользователя может только
+	// администратор
+	if state.principal != nil {
+		if userID == 0 {
+			userID = state.principal.UserID
+		} else if !principalCanAccess(state.principal, userID) {
+			return nil, newGraphQLError(codes.PermissionDenied, "Cannot create orders for another user")
+		}
+	}
+	if userID == 0 {
+		return nil, newGraphQLError(codes.InvalidArgument, "userId is required")
+	}
+
+	created, err := h.orderClient.CreateOrder(p.Context, &pbOrders.CreateOrderRequest{
+		UserId:     userID,
+		Items:      items,
+		OrderItems: orderItems,
+	})
+	if err != nil {
+		return nil, backendGraphQLError(p.Context, err)
+	}
+	return toOrderResponse(created), nil
+}
+
+func (h *Handler) resolveUpdateOrder(p graphql.ResolveParams) (interface{}, error) {
+	state := graphQLStateFrom(p.Context)
+	input := p.Args["input"].(map[string]interface{})
+
+	id, err := parseGraphQLID(p.Args["id"], "Invalid order ID format")
+	if err != nil {
+		return nil, err
+	}
+
+	updateReq := &pbOrders.UpdateOrderRequest{Id: id}
+	if raw, ok := input["items"]; ok {
+		if updateReq.OrderItems, updateReq.Items, err = graphQLOrderItems(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	var userID *uint64
+	if raw, ok := input["userId"]; ok {
+		id, err := parseGraphQLID(raw, "Invalid user ID format")
+		if err != nil {
+			return nil, err
+		}
+		userID = &id
+	}
+
+	newStatus, hasStatus := input["status"].(string)
+	if hasStatus && !order.IsKnownStatus(newStatus) {
+		return nil, newGraphQLError(codes.InvalidArgument, "Unknown order status")
+	}
+
+	current, err := h.ownedOrder(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil && *userID != current.UserId && !principalCanAccess(state.principal, *userID) {
+		return nil, newGraphQLError(codes.PermissionDenied, "Cannot reassign order to another user")
+	}
+	if hasStatus {
+		if err := order.CheckTransition(current.Status, newStatus); err != nil {
+			return nil, newGraphQLError(codes.FailedPrecondition, err.Error())
+		}
+		updateReq.Status = newStatus
+	}
+	if userID != nil {
+		updateReq.UserId = *userID
+	}
+
+	updated, err := h.orderClient.UpdateOrder(p.Context, updateReq)
+	if err != nil {
+		return nil, backendGraphQLError(p.Context, err)
+	}
+	return toOrderResponse(updated), nil
+}
+
+func (h *Handler) resolveDeleteOrder(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseGraphQLID(p.Args["id"], "Invalid order ID format")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := h.ownedOrder(p.Context, id); err != nil {
+		return nil, err
+	}
+
+	resp, err := h.orderClient.DeleteOrder(p.Context, &pbOrders.DeleteOrderRequest{Id: id})
+	if err != nil {
+		return nil, backendGraphQLError(p.Context, err)
+	}
+	if !resp.Deleted {
+		return nil, newGraphQLError(codes.NotFound, "Order not found")
+	}
+	return true, nil
+}
+
+// ownedOrder загружает заказ и проверяет, что он принадлежит текущему
+// пользователю, как loadOwnedOrder в REST API.
+func (h *Handler) ownedOrder(ctx context.Context, id uint64) (*pbOrders.OrderResponse, error) {
+	o, err := h.orderClient.GetOrder(ctx, &pbOrders.GetOrderRequest{Id: id})
+	if err != nil {
+		return nil, backendGraphQLError(ctx, err)
+	}
+	if !principalCanAccess(graphQLStateFrom(ctx).principal, o.UserId) {
+		return nil, newGraphQLError(codes.PermissionDenied, "Access to this order is denied")
+	}
+	return o, nil
+}
+
+func parseGraphQLID(raw interface{}, message string) (uint64, error) {
+	s, _ := raw.(string)
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, newGraphQLError(codes.InvalidArgument, message)
+	}
+	return id, nil
+}
+
+// graphQLOrderItems переводит список OrderItemInput в позиции заказа и
+// проверяет их так же, как order_items в REST API.
+func graphQLOrderItems(raw interface{}) ([]*pbOrders.OrderItem, []int64, error) {
+	list, _ := raw.([]interface{})
+	requests := make([]OrderItemRequest, 0, len(list))
+	for _, v := range list {
+		input := v.(map[string]interface{})
+		item := OrderItemRequest{DishID: int64(input["dishId"].(int))}
+		if quantity, ok := input["quantity"].(int); ok {
+			// Ноль buildOrderItems заменил бы на количество по умолчанию
+			if quantity < 1 || quantity > maxItemQuantity {
+				return nil, nil, newGraphQLError(codes.InvalidArgument, "quantity must be between 1 and "+strconv.Itoa(maxItemQuantity))
+			}
+			item.Quantity = int32(quantity)
+		}
+		item.Notes, _ = input["notes"].(string)
+		if modifiers, ok := input["modifiers"].([]interface{}); ok {
+			for _, m := range modifiers {
+				item.Modifiers = append(item.Modifiers, m.(string))
+			}
+		}
+		requests = append(requests, item)
+	}
+
+	orderItems, items, err := buildOrderItems(nil, requests)
+	if err != nil {
+		return nil, nil, newGraphQLError(codes.InvalidArgument, err.Error())
+	}
+	return orderItems, items, nil
+}
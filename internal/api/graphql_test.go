@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/anyviewww/bff-service/internal/api"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+)
+
+// graphQL выполняет запрос POST /graphql и возвращает разобранный ответ.
+func (e *testEnv) graphQL(status int, query string, variables map[string]interface{}, headers ...string) map[string]interface{} {
+	e.t.Helper()
+
+	req := map[string]interface{}{"query": query}
+	if variables != nil {
+		req["variables"] = variables
+	}
+	return expectStatus(e.t, e.do(http.MethodPost, "/graphql", req, headers...), status)
+}
+
+// graphQLErrorCodes возвращает extensions.code ошибок ответа.
+func graphQLErrorCodes(t *testing.T, body map[string]interface{}) []string {
+	t.Helper()
+
+	list, ok := body["errors"].([]interface{})
+	if !ok {
+		t.Fatalf("response has no errors: %v", body)
+	}
+	var codes []string
+	for _, item := range list {
+		ext, _ := item.(map[string]interface{})["extensions"].(map[string]interface{})
+		code, _ := ext["code"].(string)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func field(t *testing.T, v interface{}, path ...string) interface{} {
+	t.Helper()
+
+	for _, name := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: expected object, got %v", name, v)
+		}
+		v = obj[name]
+	}
+	return v
+}
+
+func TestGraphQLOrderWithDishes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(
+		&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1, 2, 2}, Status: "created"},
+		&pbOrders.OrderResponse{Id: 2, UserId: 5, Items: []int64{2, 3}, Status: "created"},
+		&pbOrders.OrderResponse{Id: 3, UserId: 5, Items: []int64{1, 99}, Status: "created"},
+	)
+	env.backend.Dishes.ResetCalls()
+
+	body := env.graphQL(http.StatusOK, `
+		query ($user: ID) {
+			orders(userId: $user) {
+				orders {
+					id
+					items { dishId quantity dish { name category { name } nutrition { fats } price { amountMinor amount currency } } }
+					nutritionTotals { calories proteins }
+				}
+				nextPageToken
+			}
+		}`, map[string]interface{}{"user": "5"})
+
+	// Блюда всех заказов загружаются одним запросом
+	if n := len(env.backend.Dishes.Calls("GetDishes")); n != 1 {
+		t.Errorf("GetDishes calls = %d, want 1", n)
+	}
+
+	orders := field(t, body, "data", "orders", "orders").([]interface{})
+	if len(orders) != 3 {
+		t.Fatalf("orders = %v", orders)
+	}
+	first := orders[0]
+	if got := field(t, first, "nutritionTotals", "calories"); got != float64(250+2*480) {
+		t.Errorf("calories = %v, want %v", got, 250+2*480)
+	}
+	items := field(t, first, "items").([]interface{})
+	if len(items) != 2 || field(t, items[1], "quantity") != float64(2) {
+		t.Fatalf("items = %v", items)
+	}
+	if got := field(t, items[0], "dish", "price"); got.(map[string]interface{})["amountMinor"] != "35000" {
+		t.Errorf("price = %v", got)
+	}
+	if got := field(t, items[0], "dish", "category", "name"); got != "Обед" {
+		t.Errorf("category = %v", got)
+	}
+
+	// Отсутствующее блюдо - null с ошибкой, остальные данные на месте
+	broken := orders[2]
+	brokenItems := field(t, broken, "items").([]interface{})
+	if field(t, brokenItems[0], "dish", "name") != "Борщ" || field(t, brokenItems[1], "dish") != nil {
+		t.Errorf("items of order with missing dish = %v", brokenItems)
+	}
+	if totals := field(t, broken, "nutritionTotals"); totals != nil {
+		t.Errorf("nutritionTotals = %v, want null", totals)
+	}
+	if codes := graphQLErrorCodes(t, body); len(codes) != 1 || codes[0] != "NOT_FOUND" {
+		t.Errorf("error codes = %v, want [NOT_FOUND]", codes)
+	}
+}
+
+func TestGraphQLDishes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	body := env.graphQL(http.StatusOK, `{ dishes(ids: [3, 99, 1]) { id name } dish(id: 99) { id } }`, nil)
+	if got := ids(t, field(t, body, "data", "dishes")); len(got) != 2 || got[0] != 3 || got[1] != 1 {
+		t.Errorf("dishes = %v, want [3 1]", got)
+	}
+	if dish := field(t, body, "data", "dish"); dish != nil {
+		t.Errorf("missing dish = %v, want null", dish)
+	}
+	if body["errors"] != nil {
+		t.Errorf("errors = %v", body["errors"])
+	}
+
+	// Запрос без мутаций можно выполнить через GET
+	rec := env.do(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ dishes { id } }`), nil)
+	if got := ids(t, field(t, expectStatus(t, rec, http.StatusOK), "data", "dishes")); len(got) != 4 {
+		t.Errorf("menu = %v", got)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	env := newTestEnv(t, envConfig{graphQL: api.GraphQLOptions{MaxDepth: 5, MaxComplexity: 1000}})
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{
+			name:  "depth",
+			query: `{ orders { orders { items { dish { type { name } } } } } }`,
+			code:  "QUERY_TOO_DEEP",
+		},
+		{
+			name:  "depth through fragment",
+			query: `{ orders { ...page } } fragment page on OrderConnection { orders { items { dish { type { name } } } } }`,
+			code:  "QUERY_TOO_DEEP",
+		},
+		{
+			name:  "complexity",
+			query: `{ orders(userId: "1", pageSize: 100) { orders { items { dish { name } } } } }`,
+			code:  "QUERY_TOO_COMPLEX",
+		},
+		{
+			name:  "parse error",
+			query: `{ orders {`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ orders { total } }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := env.graphQL(http.StatusBadRequest, tt.query, nil)
+			if _, ok := body["data"]; ok {
+				t.Errorf("data = %v, want no data", body["data"])
+			}
+			if codes := graphQLErrorCodes(t, body); tt.code != "" && codes[0] != tt.code {
+				t.Errorf("error codes = %v, want %s", codes, tt.code)
+			}
+		})
+	}
+
+	body := env.graphQL(http.StatusOK, `{ orders(userId: "1", pageSize: 10) { orders { items { dish { name } } } } }`, nil)
+	if body["errors"] != nil {
+		t.Errorf("query within limits: errors = %v", body["errors"])
+	}
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	body := env.graphQL(http.StatusOK, `
+		mutation ($input: CreateOrderInput!) {
+			createOrder(input: $input) { id userId status items { dishId quantity notes } }
+		}`, map[string]interface{}{"input": map[string]interface{}{
+		"userId": "7",
+		"items":  []interface{}{map[string]interface{}{"dishId": 2, "quantity": 3, "notes": "без сметаны"}},
+	}})
+	created := field(t, body, "data", "createOrder")
+	id := field(t, created, "id").(string)
+	if field(t, created, "userId") != "7" || field(t, created, "status") != "created" {
+		t.Fatalf("created order = %v", created)
+	}
+	stored, ok := env.backend.Orders.Order(1)
+	if !ok || len(stored.OrderItems) != 1 || stored.OrderItems[0].Quantity != 3 || stored.OrderItems[0].Notes != "без сметаны" {
+		t.Fatalf("stored order = %v", stored)
+	}
+
+	body = env.graphQL(http.StatusOK, `mutation ($id: ID!) { updateOrder(id: $id, input: {status: "confirmed"}) { status } }`,
+		map[string]interface{}{"id": id})
+	if got := field(t, body, "data", "updateOrder", "status"); got != "confirmed" {
+		t.Errorf("updated status = %v", got)
+	}
+
+	body = env.graphQL(http.StatusOK, `mutation ($id: ID!) { updateOrder(id: $id, input: {status: "created"}) { status } }`,
+		map[string]interface{}{"id": id})
+	if codes := graphQLErrorCodes(t, body); codes[0] != "FAILED_PRECONDITION" {
+		t.Errorf("invalid transition: error codes = %v", codes)
+	}
+
+	body = env.graphQL(http.StatusOK, `mutation { createOrder(input: {userId: "7", items: [{dishId: 1, quantity: 100}]}) { id } }`, nil)
+	if codes := graphQLErrorCodes(t, body); codes[0] != "INVALID_ARGUMENT" {
+		t.Errorf("invalid quantity: error codes = %v", codes)
+	}
+
+	body = env.graphQL(http.StatusOK, `mutation ($id: ID!) { deleteOrder(id: $id) }`, map[string]interface{}{"id": id})
+	if field(t, body, "data", "deleteOrder") != true {
+		t.Fatalf("delete = %v", body)
+	}
+	if _, ok := env.backend.Orders.Order(1); ok {
+		t.Error("order was not deleted")
+	}
+
+	// Мутации через GET запрещены
+	rec := env.do(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteOrder(id: "1") }`), nil)
+	expectStatus(t, rec, http.StatusMethodNotAllowed)
+	if n := len(env.backend.Orders.Calls("DeleteOrder")); n != 1 {
+		t.Errorf("DeleteOrder calls = %d, want 1", n)
+	}
+}
+
+func TestGraphQLAuth(t *testing.T) {
+	env := newTestEnv(t, envConfig{auth: true})
+	env.backend.Orders.Seed(
+		&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"},
+		&pbOrders.OrderResponse{Id: 2, UserId: 6, Items: []int64{1}, Status: "created"},
+	)
+	user := bearer(t, 5)
+
+	expectError(t, env.do(http.MethodPost, "/graphql", map[string]interface{}{"query": "{ dishes { id } }"}),
+		http.StatusUnauthorized, "UNAUTHENTICATED")
+
+	// По умолчанию - заказы пользователя из токена
+	body := env.graphQL(http.StatusOK, `{ orders { orders { id } } }`, nil, "Authorization", user)
+	if got := field(t, body, "data", "orders", "orders").([]interface{}); len(got) != 1 || field(t, got[0], "id") != "1" {
+		t.Errorf("own orders = %v", got)
+	}
+
+	body = env.graphQL(http.StatusOK, `{ order(id: "2") { id } }`, nil, "Authorization", user)
+	if field(t, body, "data", "order") != nil {
+		t.Errorf("foreign order = %v", field(t, body, "data", "order"))
+	}
+	if codes := graphQLErrorCodes(t, body); codes[0] != "PERMISSION_DENIED" {
+		t.Errorf("foreign order: error codes = %v", codes)
+	}
+
+	body = env.graphQL(http.StatusOK, `mutation { deleteOrder(id: "2") }`, nil, "Authorization", user)
+	if codes := graphQLErrorCodes(t, body); codes[0] != "PERMISSION_DENIED" {
+		t.Errorf("delete foreign order: error codes = %v", codes)
+	}
+	if _, ok := env.backend.Orders.Order(2); !ok {
+		t.Error("foreign order was deleted")
+	}
+
+	body = env.graphQL(http.StatusOK, `{ order(id: "2") { id } }`, nil, "Authorization", bearer(t, 1, testAdminRole))
+	if field(t, body, "data", "order", "id") != "2" {
+		t.Errorf("admin: order = %v", body)
+	}
+}
@@ -4,22 +4,59 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/anyviewww/bff-service/internal/auth"
+	"github.com/anyviewww/bff-service/internal/cart"
+	"github.com/anyviewww/bff-service/internal/health"
+	"github.com/anyviewww/bff-service/internal/idempotency"
+	"github.com/anyviewww/bff-service/internal/order"
+	"github.com/anyviewww/bff-service/internal/pricing"
 	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
 	pbOrders "github.com/anyviewww/bff-service/proto/orders"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 )
 
 type Handler struct {
 	menuClient  pbDishes.DishServiceClient
 	orderClient pbOrders.OrderServiceClient
+	menuCache   CachePurger
+	idempotency idempotency.Store
+	verifier    *auth.Verifier
+	// unauthenticatedAdmin открывает административные эндпоинты без
+	// аутентификации
+	unauthenticatedAdmin bool
+	readiness            *health.Checker
+	cart                 cart.Store
+	pricing              *pricing.Calculator
+	events               *orderEvents
+	rateLimiter          *rateLimiter
+	graphql              *graphQLServer
 }
 
-func NewHandler(menuClient pbDishes.DishServiceClient, orderClient pbOrders.OrderServiceClient) *Handler {
-	return &Handler{
+// CachePurger - кэш, который можно сбросить через административный эндпоинт
+type CachePurger interface {
+	Purge()
+}
+
+type Option func(*Handler)
+
+// WithMenuCache включает эндпоинт сброса кэша меню.
+func WithMenuCache(cache CachePurger) Option {
+	return func(h *Handler) {
+		h.menuCache = cache
+	}
+}
+
+func NewHandler(menuClient pbDishes.DishServiceClient, orderClient pbOrders.OrderServiceClient, opts ...Option) *Handler {
+	h := &Handler{
 		menuClient:  menuClient,
 		orderClient: orderClient,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Menu Handlers
@@ -27,7 +64,7 @@ func NewHandler(menuClient pbDishes.DishServiceClient, orderClient pbOrders.Orde
 func (h *Handler) GetDish(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dish ID format"})
+		respondError(c, codes.InvalidArgument, "Invalid dish ID format")
 		return
 	}
 
@@ -35,135 +72,248 @@ func (h *Handler) GetDish(c *gin.Context) {
 		Id: int32(id),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondGRPCError(c, err)
 		return
 	}
 
 	if len(resp.Dishes) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dish not found"})
+		respondError(c, codes.NotFound, "Dish not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, toDishResponse(resp.Dishes[0]))
+	respondWithETag(c, h.toDishResponse(resp.Dishes[0]))
 }
 
 func (h *Handler) GetAllDishes(c *gin.Context) {
-	resp, err := h.menuClient.GetDishes(c.Request.Context(), &pbDishes.DishRequest{})
+	query, err := parseDishQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, codes.InvalidArgument, err.Error())
 		return
 	}
 
-	dishes := make([]gin.H, 0, len(resp.Dishes))
-	for _, dish := range resp.Dishes {
-		dishes = append(dishes, toDishResponse(dish))
+	resp, err := h.menuClient.GetDishes(c.Request.Context(), &pbDishes.DishRequest{})
+	if err != nil {
+		respondGRPCError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"dishes": dishes})
+	page, nextCursor := query.apply(resp.Dishes)
+
+	result := DishListResponse{Dishes: make([]DishResponse, 0, len(page))}
+	for _, dish := range page {
+		result.Dishes = append(result.Dishes, h.toDishResponse(dish))
+	}
+	if nextCursor != "" {
+		result.NextCursor = &nextCursor
+	}
+	respondWithETag(c, result)
 }
 
-func toDishResponse(dish *pbDishes.Dish) gin.H {
-	return gin.H{
-		"id":       dish.Id,
-		"name":     dish.Name,
-		"type":     gin.H{"id": dish.Type.Id, "name": dish.Type.TypeDish},
-		"category": gin.H{"id": dish.Category.Id, "name": dish.Category.CategoryDish},
-		"nutrition": gin.H{
-			"calories":      dish.NutFact.Calories,
-			"proteins":      dish.NutFact.Proteins,
-			"fats":          dish.NutFact.Fats,
-			"carbohydrates": dish.NutFact.Carbohydrates,
+func (h *Handler) toDishResponse(dish *pbDishes.Dish) DishResponse {
+	return DishResponse{
+		ID:       dish.Id,
+		Name:     dish.Name,
+		Type:     DishTypeResponse{ID: dish.Type.Id, Name: dish.Type.TypeDish},
+		Category: DishCategoryResponse{ID: dish.Category.Id, Name: dish.Category.CategoryDish},
+		Nutrition: NutritionResponse{
+			Calories:      dish.NutFact.Calories,
+			Proteins:      dish.NutFact.Proteins,
+			Fats:          dish.NutFact.Fats,
+			Carbohydrates: dish.NutFact.Carbohydrates,
 		},
-		"tag":    gin.H{"id": dish.Tag.Id, "name": dish.Tag.TagDish},
-		"recipe": dish.Recipe,
+		Tag:    DishTagResponse{ID: dish.Tag.Id, Name: dish.Tag.TagDish},
+		Recipe: dish.Recipe,
+		Price:  h.dishPrice(dish),
+	}
+}
+
+// dishPrice возвращает цену блюда или nil, если она не задана: price = 0 в
+// DishService означает отсутствие цены, как и при расчёте заказа. Без
+// валюты цена считается в валюте расчёта по умолчанию.
+func (h *Handler) dishPrice(dish *pbDishes.Dish) *MoneyResponse {
+	if dish.GetPrice() == 0 {
+		return nil
+	}
+	currency := dish.GetCurrency()
+	if currency == "" && h.pricing != nil {
+		currency = h.pricing.Currency()
+	}
+	price := toMoneyResponse(pricing.Money{Minor: dish.GetPrice(), Currency: currency})
+	return &price
+}
+
+func (h *Handler) PurgeMenuCache(c *gin.Context) {
+	if h.menuCache == nil {
+		respondError(c, codes.Unimplemented, "Menu cache is not enabled")
+		return
 	}
+
+	h.menuCache.Purge()
+	c.JSON(http.StatusOK, MessageResponse{Message: "Menu cache purged"})
 }
 
 // Order Handlers
 
+const (
+	defaultOrdersPageSize = 20
+	maxOrdersPageSize     = 100
+)
+
+// CreateOrderRequest - тело POST /orders. Позиции передаются либо списком id
+// в items, либо в order_items. При включённой аутентификации user_id по
+// умолчанию берётся из токена.
+type CreateOrderRequest struct {
+	UserID     uint64             `json:"user_id,omitempty"`
+	Items      []int64            `json:"items,omitempty"`
+	OrderItems []OrderItemRequest `json:"order_items,omitempty"`
+}
+
+// UpdateOrderRequest - тело PUT /orders/:id. Изменяются только переданные
+// поля.
+type UpdateOrderRequest struct {
+	UserID     *uint64            `json:"user_id,omitempty"`
+	Items      []int64            `json:"items,omitempty"`
+	OrderItems []OrderItemRequest `json:"order_items,omitempty"`
+	// Status - допустимые значения подставляются в спецификацию из пакета order
+	Status *string `json:"status,omitempty"`
+}
+
 func (h *Handler) CreateOrder(c *gin.Context) {
-	var req struct {
-		UserID uint64  `json:"user_id" binding:"required"`
-		Items  []int64 `json:"items" binding:"required,min=1"`
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, codes.InvalidArgument, err.Error())
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	orderItems, items, err := buildOrderItems(req.Items, req.OrderItems)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, err.Error())
+		return
+	}
+	if len(orderItems) == 0 {
+		respondError(c, codes.InvalidArgument, "items or order_items is required")
+		return
+	}
+
+	// При включённой аутентификации пользователь берётся из токена, указать
+	// другого user_id может только администратор
+	if p, ok := principal(c); ok {
+		if req.UserID == 0 {
+			req.UserID = p.UserID
+		} else if !canAccessUser(c, req.UserID) {
+			respondError(c, codes.PermissionDenied, "Cannot create orders for another user")
+			return
+		}
+	}
+	if req.UserID == 0 {
+		respondError(c, codes.InvalidArgument, "user_id is required")
+		return
+	}
+
+	idem, handled := h.beginIdempotent(c, req)
+	if handled {
 		return
 	}
+	defer h.releaseIdempotent(c, idem)
 
 	order, err := h.orderClient.CreateOrder(c.Request.Context(), &pbOrders.CreateOrderRequest{
-		UserId: req.UserID,
-		Items:  req.Items,
+		UserId:     req.UserID,
+		Items:      items,
+		OrderItems: orderItems,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondGRPCError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, toOrderResponse(order))
+	h.completeIdempotent(c, idem, http.StatusCreated, h.orderResponse(c.Request.Context(), order))
 }
 
 func (h *Handler) GetOrder(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
 		return
 	}
 
-	order, err := h.orderClient.GetOrder(c.Request.Context(), &pbOrders.GetOrderRequest{
-		Id: id,
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	order, ok := h.loadOwnedOrder(c, id)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, toOrderResponse(order))
+	c.JSON(http.StatusOK, h.orderResponse(c.Request.Context(), order))
 }
 
 func (h *Handler) UpdateOrder(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
 		return
 	}
 
-	var req struct {
-		UserID *uint64 `json:"user_id,omitempty"`
-		Items  []int64 `json:"items,omitempty"`
-		Status *string `json:"status,omitempty"`
-	}
-
+	var req UpdateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, codes.InvalidArgument, err.Error())
 		return
 	}
 
 	updateReq := &pbOrders.UpdateOrderRequest{Id: id}
+	if req.Items != nil || req.OrderItems != nil {
+		orderItems, items, err := buildOrderItems(req.Items, req.OrderItems)
+		if err != nil {
+			respondError(c, codes.InvalidArgument, err.Error())
+			return
+		}
+		updateReq.Items = items
+		updateReq.OrderItems = orderItems
+	}
+
+	if req.Status != nil && !order.IsKnownStatus(*req.Status) {
+		respondError(c, codes.InvalidArgument, "Unknown order status")
+		return
+	}
+
+	current, ok := h.loadOwnedOrder(c, id)
+	if !ok {
+		return
+	}
+	if req.UserID != nil && *req.UserID != current.UserId && !canAccessUser(c, *req.UserID) {
+		respondError(c, codes.PermissionDenied, "Cannot reassign order to another user")
+		return
+	}
+	// Проверка не атомарна относительно OrderService, но отсекает
+	// заведомо недопустимые переходы до отправки запроса
+	if req.Status != nil {
+		if err := order.CheckTransition(current.Status, *req.Status); err != nil {
+			respondTransitionError(c, err)
+			return
+		}
+	}
+
 	if req.UserID != nil {
 		updateReq.UserId = *req.UserID
 	}
-	if req.Items != nil {
-		updateReq.Items = req.Items
-	}
 	if req.Status != nil {
 		updateReq.Status = *req.Status
 	}
 
-	order, err := h.orderClient.UpdateOrder(c.Request.Context(), updateReq)
+	updated, err := h.orderClient.UpdateOrder(c.Request.Context(), updateReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondGRPCError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, toOrderResponse(order))
+	c.JSON(http.StatusOK, h.orderResponse(c.Request.Context(), updated))
 }
 
 func (h *Handler) DeleteOrder(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
+		return
+	}
+
+	if _, ok := h.loadOwnedOrder(c, id); !ok {
 		return
 	}
 
@@ -171,34 +321,80 @@ func (h *Handler) DeleteOrder(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondGRPCError(c, err)
 		return
 	}
 
 	if resp.Deleted {
-		c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully"})
+		c.JSON(http.StatusOK, MessageResponse{Message: "Order deleted successfully"})
 	} else {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		respondError(c, codes.NotFound, "Order not found")
 	}
 }
 
 func (h *Handler) GetUserOrders(c *gin.Context) {
-	_, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid user ID format")
+		return
+	}
+
+	if !canAccessUser(c, userID) {
+		respondError(c, codes.PermissionDenied, "Access to this user's orders is denied")
+		return
+	}
+
+	pageSize := defaultOrdersPageSize
+	if s := c.Query("page_size"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size < 1 || size > maxOrdersPageSize {
+			respondError(c, codes.InvalidArgument, "Invalid page_size value")
+			return
+		}
+		pageSize = size
+	}
+
+	resp, err := h.orderClient.ListOrders(c.Request.Context(), &pbOrders.ListOrdersRequest{
+		UserId:    userID,
+		Status:    c.Query("status"),
+		PageSize:  int32(pageSize),
+		PageToken: c.Query("page_token"),
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		respondGRPCError(c, err)
 		return
 	}
 
-	// В текущей реализации proto нет метода для получения заказов пользователя
-	// Это пример того, как можно реализовать, если добавить метод в OrderService
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	result := OrderListResponse{Orders: h.orderResponses(c.Request.Context(), resp.Orders...)}
+	if resp.NextPageToken != "" {
+		result.NextPageToken = &resp.NextPageToken
+	}
+	c.JSON(http.StatusOK, result)
 }
 
-func toOrderResponse(order *pbOrders.OrderResponse) gin.H {
-	return gin.H{
-		"id":      order.Id,
-		"user_id": order.UserId,
-		"items":   order.Items,
-		"status":  order.Status,
+// toOrderResponse отдаёт позиции в обоих форматах: items для старых
+// клиентов и order_items с количеством и комментариями.
+func toOrderResponse(order *pbOrders.OrderResponse) OrderResponse {
+	orderItems := orderItemsOf(order)
+
+	items := order.Items
+	if len(items) == 0 {
+		items = flattenOrderItems(orderItems)
+	}
+	if items == nil {
+		items = []int64{}
+	}
+
+	itemResponses := make([]OrderItemResponse, 0, len(orderItems))
+	for _, item := range orderItems {
+		itemResponses = append(itemResponses, toOrderItemResponse(item))
+	}
+
+	return OrderResponse{
+		ID:         order.Id,
+		UserID:     order.UserId,
+		Items:      items,
+		OrderItems: itemResponses,
+		Status:     order.Status,
 	}
 }
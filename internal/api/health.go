@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/anyviewww/bff-service/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithReadiness подключает проверку бэкендов к /readyz. Без неё сервис
+// считается готовым, пока не начал завершаться.
+func WithReadiness(checker *health.Checker) Option {
+	return func(h *Handler) {
+		h.readiness = checker
+	}
+}
+
+func (h *Handler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+func (h *Handler) Readyz(c *gin.Context) {
+	if h.readiness == nil {
+		c.JSON(http.StatusOK, HealthResponse{Status: "ready"})
+		return
+	}
+
+	report := h.readiness.Check(c.Request.Context())
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "not_ready", Details: &report})
+		return
+	}
+	c.JSON(http.StatusOK, HealthResponse{Status: "ready", Details: &report})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/anyviewww/bff-service/internal/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// WithIdempotencyStore включает поддержку заголовка Idempotency-Key.
+func WithIdempotencyStore(store idempotency.Store) Option {
+	return func(h *Handler) {
+		h.idempotency = store
+	}
+}
+
+// idempotentRequest - зарезервированный ключ идемпотентности запроса
+type idempotentRequest struct {
+	key         string
+	fingerprint string
+	// done - ответ сохранён или резервирование уже снято
+	done bool
+}
+
+// beginIdempotent резервирует ключ идемпотентности из заголовка запроса.
+// Возвращает nil, если заголовка нет или хранилище не настроено, и признак
+// того, что ответ уже отправлен: сохранённый результат или ошибка. Сразу
+// после резервирования нужно отложить releaseIdempotent.
+func (h *Handler) beginIdempotent(c *gin.Context, req interface{}) (*idempotentRequest, bool) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" || h.idempotency == nil {
+		return nil, false
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		respondError(c, codes.InvalidArgument, "Idempotency-Key is too long")
+		return nil, true
+	}
+
+	fingerprint, err := requestFingerprint(c, req)
+	if err != nil {
+		respondError(c, codes.Internal, internalErrorMessage)
+		return nil, true
+	}
+
+	// Ключи разных пользователей не должны пересекаться
+	if p, ok := principal(c); ok {
+		key = p.Subject + ":" + key
+	}
+
+	record, err := h.idempotency.Begin(c.Request.Context(), key, fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrInProgress):
+		respondError(c, codes.Aborted, err.Error())
+		return nil, true
+	case errors.Is(err, idempotency.ErrFingerprintMismatch):
+		respondHTTPError(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", err.Error())
+		return nil, true
+	case err != nil:
+		slog.ErrorContext(c.Request.Context(), "idempotency store error", slog.Any("error", err))
+		respondError(c, codes.Unavailable, "Idempotency store is unavailable")
+		return nil, true
+	}
+
+	if record != nil {
+		c.Header(idempotentReplayHeader, "true")
+		c.Data(record.StatusCode, "application/json; charset=utf-8", record.Body)
+		return nil, true
+	}
+	return &idempotentRequest{key: key, fingerprint: fingerprint}, false
+}
+
+// completeIdempotent отправляет успешный ответ и сохраняет его по ключу.
+func (h *Handler) completeIdempotent(c *gin.Context, ir *idempotentRequest, statusCode int, obj interface{}) {
+	if ir == nil {
+		c.JSON(statusCode, obj)
+		return
+	}
+
+	body, err := json.Marshal(obj)
+	if err != nil {
+		respondError(c, codes.Internal, internalErrorMessage)
+		return
+	}
+
+	// Если сохранить ответ не удалось, резервирование снимет
+	// releaseIdempotent: повтор выполнит запрос заново
+	record := idempotency.Record{Fingerprint: ir.fingerprint, StatusCode: statusCode, Body: body}
+	if err := h.idempotency.Complete(context.WithoutCancel(c.Request.Context()), ir.key, record); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to store idempotent response",
+			slog.String("key", ir.key), slog.Any("error", err))
+	} else {
+		ir.done = true
+	}
+
+	c.Data(statusCode, "application/json; charset=utf-8", body)
+}
+
+// releaseIdempotent снимает резервирование ключа, если ответ не был
+// сохранён, чтобы клиент мог повторить запрос. Вызывается через defer, в том
+// числе при панике в обработчике.
+func (h *Handler) releaseIdempotent(c *gin.Context, ir *idempotentRequest) {
+	if ir == nil || ir.done {
+		return
+	}
+	ir.done = true
+	if err := h.idempotency.Release(context.WithoutCancel(c.Request.Context()), ir.key); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to release idempotency key",
+			slog.String("key", ir.key), slog.Any("error", err))
+	}
+}
+
+// requestFingerprint строится по разобранному телу, а не по сырым байтам,
+// чтобы форматирование JSON не влияло на сравнение.
+func requestFingerprint(c *gin.Context, req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...))
+	return hex.EncodeToString(sum[:]), nil
+}
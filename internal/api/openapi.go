@@ -0,0 +1,395 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+	"sync"
+
+	"github.com/anyviewww/bff-service/internal/openapi"
+	"github.com/anyviewww/bff-service/internal/order"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiRoutes описывает маршруты SetupRoutes для спецификации OpenAPI. Тест
+// сверяет этот список с маршрутами gin, поэтому новый маршрут нужно
+// добавить в оба места.
+func apiRoutes() []openapi.Route {
+	orderID := pathParam("id", "ID заказа", openapi.Integer("int64", openapi.Bound(0), nil))
+	orderResponse := []openapi.RouteResponse{{Status: http.StatusOK, Body: OrderResponse{}}}
+	cartUser := queryParam("user_id", "Владелец корзины, если аутентификация выключена",
+		openapi.Integer("int64", openapi.Bound(1), nil))
+	cartDishID := pathParam("dish_id", "ID блюда", openapi.Integer("int64", nil, nil))
+	cartResponse := []openapi.RouteResponse{{Status: http.StatusOK, Body: CartResponse{}}}
+	idempotencyKey := headerParam(idempotencyKeyHeader,
+		"Ключ идемпотентности: повторный запрос с тем же ключом вернёт сохранённый ответ",
+		&openapi.Schema{Type: "string", MaxLength: intPtr(maxIdempotencyKeyLength)})
+
+	eventID := &openapi.Schema{Type: "string", Pattern: `^[0-9]+$`}
+
+	statusAction := func(action, target, summary string) openapi.Route {
+		return openapi.Route{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/orders/:id/" + action,
+			OperationID: action + "Order",
+			Summary:     summary,
+			Description: "Переводит заказ в статус " + target + ". Повторный перевод в текущий статус ничего не меняет.",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{orderID},
+			Responses:   orderResponse,
+			Secured:     true,
+		}
+	}
+
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/menu/dishes",
+			OperationID: "listDishes",
+			Summary:     "Список блюд",
+			Description: "Фильтры по id принимают несколько значений через запятую. Ответ поддерживает If-None-Match.",
+			Tag:         "menu",
+			Params:      dishQueryParams(),
+			Responses:   etagResponses(DishListResponse{}),
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/menu/dishes/:id",
+			OperationID: "getDish",
+			Summary:     "Блюдо",
+			Tag:         "menu",
+			Params:      []*openapi.Parameter{pathParam("id", "ID блюда", openapi.Integer("int32", nil, nil))},
+			Responses:   etagResponses(DishResponse{}),
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/orders/",
+			OperationID: "createOrder",
+			Summary:     "Создать заказ",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{idempotencyKey},
+			Body:        CreateOrderRequest{},
+			Responses:   []openapi.RouteResponse{{Status: http.StatusCreated, Body: OrderResponse{}}},
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/orders/:id",
+			OperationID: "getOrder",
+			Summary:     "Заказ",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{orderID},
+			Responses:   orderResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/orders/:id/details",
+			OperationID: "getOrderDetails",
+			Summary:     "Заказ с блюдами и пищевой ценностью",
+			Description: "Блюда, которые не удалось получить, отмечаются в позиции, ответ при этом остаётся успешным с partial = true.",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{orderID},
+			Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Body: OrderDetailsResponse{}}},
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/orders/:id/events",
+			OperationID: "streamOrderEventsSSE",
+			Summary:     "Изменения заказа (Server-Sent Events)",
+			Description: "События order содержат OrderEventResponse, id события - его номер. " +
+				"Поток завершается событием end (StreamEndResponse) или error (ErrorResponse).",
+			Tag: "orders",
+			Params: []*openapi.Parameter{
+				orderID,
+				headerParam("Last-Event-ID", "Номер последнего полученного события", eventID),
+				queryParam("last_event_id", "То же, что Last-Event-ID, для первого подключения EventSource", eventID),
+			},
+			Responses: []openapi.RouteResponse{{
+				Status:      http.StatusOK,
+				Description: "Поток событий",
+				Body:        OrderEventResponse{},
+				ContentType: "text/event-stream",
+			}},
+			Secured: true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/orders/:id/events/ws",
+			OperationID: "streamOrderEventsWebSocket",
+			Summary:     "Изменения заказа (WebSocket)",
+			Description: "После upgrade сервер отправляет сообщения OrderStreamMessage.",
+			Tag:         "orders",
+			Params: []*openapi.Parameter{
+				orderID,
+				headerParam("Last-Event-ID", "Номер последнего полученного события", eventID),
+				queryParam("last_event_id", "То же, что Last-Event-ID", eventID),
+			},
+			Responses: []openapi.RouteResponse{{
+				Status:      http.StatusSwitchingProtocols,
+				Description: "Соединение WebSocket",
+				Body:        OrderStreamMessage{},
+			}},
+			Secured: true,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/api/v1/orders/:id",
+			OperationID: "updateOrder",
+			Summary:     "Изменить заказ",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{orderID},
+			Body:        UpdateOrderRequest{},
+			Responses:   orderResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/orders/:id",
+			OperationID: "deleteOrder",
+			Summary:     "Удалить заказ",
+			Tag:         "orders",
+			Params:      []*openapi.Parameter{orderID},
+			Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Body: MessageResponse{}}},
+			Secured:     true,
+		},
+		statusAction("confirm", "confirmed", "Подтвердить заказ"),
+		statusAction("cook", "cooking", "Начать готовить заказ"),
+		statusAction("ready", "ready", "Отметить заказ готовым"),
+		statusAction("deliver", "delivered", "Отметить заказ доставленным"),
+		statusAction("cancel", "cancelled", "Отменить заказ"),
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/cart",
+			OperationID: "getCart",
+			Summary:     "Корзина",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartUser},
+			Responses:   cartResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/cart",
+			OperationID: "clearCart",
+			Summary:     "Очистить корзину",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartUser},
+			Responses:   cartResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/cart/items",
+			OperationID: "addCartItem",
+			Summary:     "Добавить блюдо в корзину",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartUser},
+			Body:        AddCartItemRequest{},
+			Responses:   cartResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/api/v1/cart/items/:dish_id",
+			OperationID: "setCartItemQuantity",
+			Summary:     "Изменить количество блюда в корзине",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartDishID, cartUser},
+			Body:        SetCartItemQuantityRequest{},
+			Responses:   cartResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/cart/items/:dish_id",
+			OperationID: "removeCartItem",
+			Summary:     "Убрать блюдо из корзины",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartDishID, cartUser},
+			Responses:   cartResponse,
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/cart/checkout",
+			OperationID: "checkoutCart",
+			Summary:     "Оформить заказ из корзины",
+			Tag:         "cart",
+			Params:      []*openapi.Parameter{cartUser, idempotencyKey},
+			Responses:   []openapi.RouteResponse{{Status: http.StatusCreated, Body: OrderResponse{}}},
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/cache/menu/purge",
+			OperationID: "purgeMenuCache",
+			Summary:     "Сбросить кэш меню",
+			Description: "Требует роль администратора.",
+			Tag:         "admin",
+			Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Body: MessageResponse{}}},
+			Secured:     true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/users/:user_id/orders",
+			OperationID: "listUserOrders",
+			Summary:     "Заказы пользователя",
+			Tag:         "orders",
+			Params: []*openapi.Parameter{
+				pathParam("user_id", "ID пользователя", openapi.Integer("int64", openapi.Bound(0), nil)),
+				queryParam("status", "Фильтр по статусу", openapi.String()),
+				queryParam("page_size", "Размер страницы",
+					openapi.Integer("int32", openapi.Bound(1), openapi.Bound(maxOrdersPageSize))),
+				queryParam("page_token", "Токен страницы из next_page_token", openapi.String()),
+			},
+			Responses: []openapi.RouteResponse{{Status: http.StatusOK, Body: OrderListResponse{}}},
+			Secured:   true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/graphql",
+			OperationID: "graphql",
+			Summary:     "Запрос GraphQL",
+			Description: "Схема доступна через интроспекцию. Запросы ограничены по глубине и сложности. " +
+				"Ошибки разбора и лимитов возвращаются с кодом 400, ошибки резолверов - в errors ответа 200.",
+			Tag:       "graphql",
+			Body:      GraphQLRequest{},
+			Responses: []openapi.RouteResponse{{Status: http.StatusOK, Body: GraphQLResponse{}}},
+			Secured:   true,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/graphql",
+			OperationID: "graphqlQuery",
+			Summary:     "Запрос GraphQL без мутаций",
+			Tag:         "graphql",
+			Params: []*openapi.Parameter{
+				{Name: "query", In: openapi.InQuery, Description: "Текст запроса", Required: true,
+					Schema: &openapi.Schema{Type: "string", MinLength: intPtr(1)}},
+				queryParam("operationName", "Имя операции, если их в запросе несколько", openapi.String()),
+				queryParam("variables", "Переменные - JSON-объект", openapi.String()),
+			},
+			Responses: []openapi.RouteResponse{{Status: http.StatusOK, Body: GraphQLResponse{}}},
+			Secured:   true,
+		},
+		healthRoute("/livez", "livez", "Процесс жив"),
+		{
+			Method:      http.MethodGet,
+			Path:        "/readyz",
+			OperationID: "readyz",
+			Summary:     "Готовность принимать трафик",
+			Tag:         "health",
+			Responses: []openapi.RouteResponse{
+				{Status: http.StatusOK, Body: HealthResponse{}},
+				{Status: http.StatusServiceUnavailable, Description: "Сервис не готов", Body: HealthResponse{}},
+			},
+		},
+		healthRoute("/health", "health", "То же, что /livez"),
+		{
+			Method:      http.MethodGet,
+			Path:        "/openapi.json",
+			OperationID: "getOpenAPI",
+			Summary:     "Эта спецификация",
+			Tag:         "docs",
+			Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Description: "Документ OpenAPI 3"}},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/docs",
+			OperationID: "getAPIDocs",
+			Summary:     "Swagger UI",
+			Tag:         "docs",
+			Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Description: "HTML-страница"}},
+		},
+	}
+}
+
+func dishQueryParams() []*openapi.Parameter {
+	idList := &openapi.Schema{Type: "string", Pattern: `^-?\d+(\s*,\s*-?\d+)*$`}
+	params := []*openapi.Parameter{
+		queryParam("type_id", "ID типов через запятую", idList),
+		queryParam("category_id", "ID категорий через запятую", idList),
+		queryParam("tag_id", "ID тегов через запятую", idList),
+		queryParam("q", "Подстрока названия без учёта регистра", openapi.String()),
+	}
+	for _, name := range []string{"calories", "proteins", "fats", "carbohydrates"} {
+		params = append(params,
+			queryParam("min_"+name, "", openapi.Number()),
+			queryParam("max_"+name, "", openapi.Number()),
+		)
+	}
+	return append(params,
+		queryParam("sort", "Поле сортировки, минус - по убыванию",
+			&openapi.Schema{Type: "string", Pattern: `^-?(id|name|calories|proteins|fats|carbohydrates)$`}),
+		queryParam("limit", "Размер страницы",
+			openapi.Integer("int32", openapi.Bound(1), openapi.Bound(maxDishesLimit))),
+		queryParam("cursor", "Курсор из next_cursor", openapi.String()),
+	)
+}
+
+func etagResponses(body interface{}) []openapi.RouteResponse {
+	return []openapi.RouteResponse{
+		{Status: http.StatusOK, Body: body},
+		{Status: http.StatusNotModified, Description: "Ответ не изменился с указанного в If-None-Match ETag"},
+	}
+}
+
+func healthRoute(path, operationID, summary string) openapi.Route {
+	return openapi.Route{
+		Method:      http.MethodGet,
+		Path:        path,
+		OperationID: operationID,
+		Summary:     summary,
+		Tag:         "health",
+		Responses:   []openapi.RouteResponse{{Status: http.StatusOK, Body: HealthResponse{}}},
+	}
+}
+
+func pathParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InPath, Description: description, Required: true, Schema: schema}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InQuery, Description: description, Schema: schema}
+}
+
+func headerParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InHeader, Description: description, Schema: schema}
+}
+
+func intPtr(v int) *int { return &v }
+
+var openAPIDocument = sync.OnceValue(func() *openapi.Document {
+	doc := openapi.Build(openapi.Info{
+		Title:       "BFF Service API",
+		Description: "HTTP API поверх DishService и OrderService.",
+		Version:     "1.0.0",
+	}, apiRoutes(), ErrorResponse{})
+
+	// Статусы берутся из модели заказа, чтобы проверка запроса не
+	// расходилась с order.CheckTransition при добавлении статуса
+	doc.Components.Schemas["UpdateOrderRequest"].Properties["status"].Enum = order.Statuses()
+	return doc
+})
+
+// OpenAPI возвращает спецификацию HTTP API. Документ строится один раз и не
+// должен изменяться.
+func OpenAPI() *openapi.Document {
+	return openAPIDocument()
+}
+
+func (h *Handler) OpenAPISpec(c *gin.Context) {
+	c.JSON(http.StatusOK, OpenAPI())
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// APIDocs отдаёт Swagger UI для /openapi.json.
+func (h *Handler) APIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,140 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/anyviewww/bff-service/internal/api"
+	"github.com/anyviewww/bff-service/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestOpenAPIMatchesRoutes падает, если маршрут добавлен в SetupRoutes, но
+// не описан в спецификации, или наоборот.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	engine := gin.New()
+	api.NewRouter(api.NewHandler(nil, nil)).SetupRoutes(engine)
+
+	var registered []string
+	for _, r := range engine.Routes() {
+		registered = append(registered, r.Method+" "+openapi.PathFromGin(r.Path))
+	}
+	sort.Strings(registered)
+
+	documented := api.OpenAPI().Operations()
+
+	missing, extra := diff(registered, documented), diff(documented, registered)
+	for _, op := range missing {
+		t.Errorf("route %s is not described in the OpenAPI document", op)
+	}
+	for _, op := range extra {
+		t.Errorf("OpenAPI document describes %s, which is not registered", op)
+	}
+}
+
+func diff(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+	}
+	var result []string
+	for _, s := range a {
+		if !inB[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func TestOpenAPIDocumentIsConsistent(t *testing.T) {
+	doc := api.OpenAPI()
+
+	operationIDs := make(map[string]string)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			where := strings.ToUpper(method) + " " + path
+
+			if prev, ok := operationIDs[op.OperationID]; ok {
+				t.Errorf("%s: operationId %q is already used by %s", where, op.OperationID, prev)
+			}
+			operationIDs[op.OperationID] = where
+
+			declared := make(map[string]bool)
+			for _, p := range op.Parameters {
+				if p.In == openapi.InPath {
+					declared[p.Name] = true
+				}
+			}
+			for _, m := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				if !declared[m[1]] {
+					t.Errorf("%s: path parameter %s is not declared", where, m[1])
+				}
+				delete(declared, m[1])
+			}
+			for name := range declared {
+				t.Errorf("%s: declared path parameter %s is not in the path", where, name)
+			}
+		}
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		t.Fatal(err)
+	}
+	walkRefs(tree, func(ref string) {
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		if !ok || doc.Components.Schemas[name] == nil {
+			t.Errorf("unresolved $ref %q", ref)
+		}
+	})
+}
+
+func walkRefs(node interface{}, visit func(string)) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				visit(ref)
+				continue
+			}
+			walkRefs(child, visit)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkRefs(child, visit)
+		}
+	}
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	env := newTestEnv(t, envConfig{auth: true})
+
+	rec := env.do(http.MethodGet, "/openapi.json", nil)
+	body := expectStatus(t, rec, http.StatusOK)
+	if body["openapi"] != openapi.Version {
+		t.Fatalf("openapi = %v, want %s", body["openapi"], openapi.Version)
+	}
+	paths := body["paths"].(map[string]interface{})
+	if _, ok := paths["/api/v1/orders/{id}"]; !ok {
+		t.Fatalf("spec has no /api/v1/orders/{id}: %v", paths)
+	}
+
+	rec = env.do(http.MethodGet, "/docs", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /docs: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "openapi.json") {
+		t.Fatal("docs page does not reference openapi.json")
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// Ограничение на число одновременных запросов в DishService из одного заказа
+const maxConcurrentDishLookups = 8
+
+type dishLookup struct {
+	dish *pbDishes.Dish
+	err  string
+	code codes.Code
+}
+
+func (h *Handler) GetOrderDetails(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
+		return
+	}
+
+	order, ok := h.loadOwnedOrder(c, id)
+	if !ok {
+		return
+	}
+
+	// Уникальные id блюд в порядке первого появления
+	orderItems := orderItemsOf(order)
+	var dishIDs []int64
+	seen := make(map[int64]bool)
+	for _, item := range orderItems {
+		if !seen[item.DishId] {
+			seen[item.DishId] = true
+			dishIDs = append(dishIDs, item.DishId)
+		}
+	}
+
+	lookups := h.lookupDishes(c.Request.Context(), dishIDs)
+
+	var calories, proteins, fats, carbohydrates float64
+	items := make([]OrderDetailsItem, 0, len(orderItems))
+	unresolved := make([]int64, 0)
+	for _, orderItem := range orderItems {
+		lookup := lookups[orderItem.DishId]
+
+		item := OrderDetailsItem{
+			OrderItemResponse: toOrderItemResponse(orderItem),
+			Resolved:          lookup.dish != nil,
+		}
+		if lookup.dish == nil {
+			item.Error = lookup.err
+			if !containsDishID(unresolved, orderItem.DishId) {
+				unresolved = append(unresolved, orderItem.DishId)
+			}
+			items = append(items, item)
+			continue
+		}
+
+		dish := h.toDishResponse(lookup.dish)
+		item.Dish = &dish
+		items = append(items, item)
+
+		qty := float64(orderItem.Quantity)
+		nf := lookup.dish.GetNutFact()
+		calories += float64(nf.GetCalories()) * qty
+		proteins += float64(nf.GetProteins()) * qty
+		fats += float64(nf.GetFats()) * qty
+		carbohydrates += float64(nf.GetCarbohydrates()) * qty
+	}
+
+	result := OrderDetailsResponse{
+		ID:     order.Id,
+		UserID: order.UserId,
+		Status: order.Status,
+		Items:  items,
+		NutritionTotals: NutritionTotalsResponse{
+			Calories:      calories,
+			Proteins:      proteins,
+			Fats:          fats,
+			Carbohydrates: carbohydrates,
+		},
+		Partial:           len(unresolved) > 0,
+		UnresolvedDishIDs: unresolved,
+	}
+	if h.pricing != nil {
+		result.Pricing = h.priceOrder(c.Request.Context(), orderItems, lookups)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// lookupDishes параллельно запрашивает блюда в DishService. Ошибки по отдельным
+// блюдам не прерывают остальные запросы, а возвращаются в результате.
+func (h *Handler) lookupDishes(ctx context.Context, dishIDs []int64) map[int64]dishLookup {
+	results := make(map[int64]dishLookup, len(dishIDs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentDishLookups)
+
+	for _, dishID := range dishIDs {
+		if dishID < math.MinInt32 || dishID > math.MaxInt32 {
+			results[dishID] = dishLookup{err: "Invalid dish ID", code: codes.InvalidArgument}
+			continue
+		}
+
+		wg.Add(1)
+		go func(dishID int64) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var result dishLookup
+			resp, err := h.menuClient.GetDishes(ctx, &pbDishes.DishRequest{Id: int32(dishID)})
+			switch {
+			case err != nil:
+				result.err = publicErrorMessage(err)
+				result.code = grpcStatus(err).Code()
+			case len(resp.Dishes) == 0:
+				result.err = "Dish not found"
+				result.code = codes.NotFound
+			default:
+				result.dish = resp.Dishes[0]
+			}
+
+			mu.Lock()
+			results[dishID] = result
+			mu.Unlock()
+		}(dishID)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func containsDishID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
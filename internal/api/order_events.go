@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/watch"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// Через сколько клиент EventSource переподключается после обрыва
+	sseRetry = 3 * time.Second
+	// Время на отправку одного сообщения WebSocket
+	wsWriteTimeout = 10 * time.Second
+)
+
+type OrderEventsOptions struct {
+	// Heartbeat - период комментариев SSE и ping WebSocket, которые не дают
+	// прокси закрыть простаивающее соединение
+	Heartbeat time.Duration
+	// AllowedOrigins - источники, с которых разрешено открывать WebSocket,
+	// кроме собственного. "*" разрешает любые
+	AllowedOrigins []string
+}
+
+type orderEvents struct {
+	watcher  *watch.Watcher
+	opts     OrderEventsOptions
+	upgrader websocket.Upgrader
+
+	// websockets - активные соединения WebSocket: http.Server.Shutdown
+	// не ждёт перехваченные соединения
+	websockets sync.WaitGroup
+}
+
+// WithOrderEvents включает потоки изменений заказов через SSE и WebSocket.
+func WithOrderEvents(watcher *watch.Watcher, opts OrderEventsOptions) Option {
+	return func(h *Handler) {
+		h.events = &orderEvents{
+			watcher: watcher,
+			opts:    opts,
+			upgrader: websocket.Upgrader{
+				CheckOrigin: originChecker(opts.AllowedOrigins),
+			},
+		}
+	}
+}
+
+// subscribeOrder проверяет доступ к заказу и подписывается на него. При
+// ошибке ответ уже отправлен и возвращается nil.
+func (h *Handler) subscribeOrder(c *gin.Context) *watch.Subscription {
+	if h.events == nil {
+		respondError(c, codes.Unimplemented, "Order events are not enabled")
+		return nil
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
+		return nil
+	}
+
+	// EventSource передаёт Last-Event-ID заголовком только при
+	// переподключении, поэтому его можно указать и параметром
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastSequence uint64
+	if lastEventID != "" {
+		if lastSequence, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			respondError(c, codes.InvalidArgument, "Invalid Last-Event-ID")
+			return nil
+		}
+	}
+
+	if _, ok := h.loadOwnedOrder(c, id); !ok {
+		return nil
+	}
+
+	sub, err := h.events.watcher.Subscribe(id, lastSequence)
+	if err != nil {
+		respondError(c, codes.Unavailable, err.Error())
+		return nil
+	}
+	return sub
+}
+
+// OrderEventsSSE отдаёт изменения заказа как Server-Sent Events. Каждое
+// событие order содержит заказ целиком, id события - его номер для
+// возобновления через Last-Event-ID.
+func (h *Handler) OrderEventsSSE(c *gin.Context) {
+	sub := h.subscribeOrder(c)
+	if sub == nil {
+		return
+	}
+	defer sub.Close()
+
+	// Поток живёт дольше WriteTimeout сервера
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(c.Request.Context(), "cannot disable write deadline for SSE", slog.Any("error", err))
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry.Milliseconds())
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.events.opts.Heartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-sub.Events():
+			if !ok {
+				name, data := streamEndMessage(c, sub.Err())
+				writeSSE(c, "", name, data)
+				return
+			}
+			data, err := json.Marshal(h.orderEventResponse(c, ev))
+			if err != nil {
+				return
+			}
+			if !writeSSE(c, strconv.FormatUint(ev.Sequence, 10), "order", data) {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeSSE(c *gin.Context, id, event string, data []byte) bool {
+	var b strings.Builder
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	b.WriteString("event: " + event + "\n")
+	b.WriteString("data: ")
+	b.Write(data)
+	b.WriteString("\n\n")
+
+	if _, err := c.Writer.WriteString(b.String()); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// OrderEventsWebSocket отдаёт те же события через WebSocket. Сообщения -
+// JSON с полем type: order, end или error. Соединение закрывается сервером
+// после end или error.
+func (h *Handler) OrderEventsWebSocket(c *gin.Context) {
+	sub := h.subscribeOrder(c)
+	if sub == nil {
+		return
+	}
+	defer sub.Close()
+
+	h.events.websockets.Add(1)
+	defer h.events.websockets.Done()
+
+	conn, err := h.events.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade уже отправил ответ с ошибкой
+		return
+	}
+	defer conn.Close()
+
+	heartbeat := h.events.opts.Heartbeat
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+
+	// Клиент ничего не отправляет, чтение нужно только для обработки
+	// pong и close и обнаружения обрыва
+	clientGone := make(chan struct{})
+	go func() {
+		defer close(clientGone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	write := func(v interface{}) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(v) == nil
+	}
+
+	for {
+		select {
+		case <-clientGone:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case ev, ok := <-sub.Events():
+			if !ok {
+				name, data := streamEndMessage(c, sub.Err())
+				write(OrderStreamMessage{Type: name, Data: data})
+				closeCode := websocket.CloseNormalClosure
+				if name == "error" {
+					closeCode = websocket.CloseTryAgainLater
+				}
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(closeCode, name), time.Now().Add(wsWriteTimeout))
+				return
+			}
+			event := h.orderEventResponse(c, ev)
+			if !write(OrderStreamMessage{Type: "order", OrderEventResponse: &event}) {
+				return
+			}
+		}
+	}
+}
+
+// WaitOrderStreams ждёт завершения соединений WebSocket после остановки
+// подписок или отмены ctx.
+func (h *Handler) WaitOrderStreams(ctx context.Context) {
+	if h.events == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.events.websockets.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+}
+
+func (h *Handler) orderEventResponse(c *gin.Context, ev watch.Event) OrderEventResponse {
+	return OrderEventResponse{
+		ID:         strconv.FormatUint(ev.Sequence, 10),
+		OccurredAt: ev.OccurredAt.UTC(),
+		Order:      h.orderResponse(c.Request.Context(), ev.Order),
+	}
+}
+
+// streamEndMessage описывает завершение подписки: end, если заказ перешёл
+// в конечный статус, иначе error в формате ошибок API.
+func streamEndMessage(c *gin.Context, err error) (string, []byte) {
+	if err == nil {
+		data, _ := json.Marshal(StreamEndResponse{Reason: "order is final"})
+		return "end", data
+	}
+
+	st := grpcStatus(err)
+	m := mappingFor(st.Code())
+	message := publicErrorMessage(err)
+	switch {
+	case errors.Is(err, watch.ErrShuttingDown):
+		m, message = mappingFor(codes.Unavailable), err.Error()
+	case errors.Is(err, watch.ErrLagging):
+		m, message = mappingFor(codes.ResourceExhausted), err.Error()
+	}
+
+	data, _ := json.Marshal(errorResponse(c, m, message, nil))
+	return "error", data
+}
+
+// originChecker разрешает запросы без Origin (не из браузера), с того же
+// хоста и из разрешённых источников.
+func originChecker(allowed []string) func(*http.Request) bool {
+	origins := make(map[string]bool, len(allowed))
+	for _, origin := range allowed {
+		origins[origin] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || origins["*"] || origins[origin] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+)
+
+const (
+	maxItemQuantity     = 99
+	maxItemNotesLength  = 500
+	maxItemModifiers    = 10
+	maxItemModifierSize = 64
+)
+
+// OrderItemRequest - позиция заказа в теле запроса. Quantity по умолчанию 1.
+// Ограничения в тегах openapi совпадают с константами maxItem*.
+type OrderItemRequest struct {
+	DishID    int64    `json:"dish_id" binding:"required" openapi:"minimum=1"`
+	Quantity  int32    `json:"quantity,omitempty" openapi:"minimum=0,maximum=99"`
+	Notes     string   `json:"notes,omitempty" openapi:"maxLength=500"`
+	Modifiers []string `json:"modifiers,omitempty" openapi:"maxItems=10,items.minLength=1,items.maxLength=64"`
+}
+
+// buildOrderItems принимает позиции в одном из двух форматов: устаревший
+// список id в items или order_items с количеством и комментариями. Результат
+// возвращается в обоих форматах, чтобы заказ понимали и старые версии
+// OrderService.
+func buildOrderItems(legacy []int64, items []OrderItemRequest) ([]*pbOrders.OrderItem, []int64, error) {
+	if len(legacy) > 0 && len(items) > 0 {
+		return nil, nil, errors.New("use either items or order_items, not both")
+	}
+
+	if len(items) == 0 {
+		return orderItemsFromLegacy(legacy), legacy, nil
+	}
+
+	result := make([]*pbOrders.OrderItem, 0, len(items))
+	for i, item := range items {
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if err := validateOrderItem(item); err != nil {
+			return nil, nil, fmt.Errorf("order_items[%d]: %w", i, err)
+		}
+		result = append(result, &pbOrders.OrderItem{
+			DishId:    item.DishID,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			Modifiers: item.Modifiers,
+		})
+	}
+	return result, flattenOrderItems(result), nil
+}
+
+func validateOrderItem(item OrderItemRequest) error {
+	if item.DishID <= 0 {
+		return errors.New("dish_id must be positive")
+	}
+	if item.Quantity < 1 || item.Quantity > maxItemQuantity {
+		return fmt.Errorf("quantity must be between 1 and %d", maxItemQuantity)
+	}
+	if utf8.RuneCountInString(item.Notes) > maxItemNotesLength {
+		return fmt.Errorf("notes must not exceed %d characters", maxItemNotesLength)
+	}
+	if len(item.Modifiers) > maxItemModifiers {
+		return fmt.Errorf("at most %d modifiers are allowed", maxItemModifiers)
+	}
+	for _, m := range item.Modifiers {
+		if m == "" || utf8.RuneCountInString(m) > maxItemModifierSize {
+			return fmt.Errorf("modifiers must be non-empty and at most %d characters", maxItemModifierSize)
+		}
+	}
+	return nil
+}
+
+// orderItemsOf возвращает позиции заказа. Если OrderService не заполнил
+// order_items, позиции восстанавливаются из items: одинаковые id
+// объединяются в одну позицию в порядке первого появления.
+func orderItemsOf(order *pbOrders.OrderResponse) []*pbOrders.OrderItem {
+	if len(order.OrderItems) > 0 {
+		return order.OrderItems
+	}
+	return orderItemsFromLegacy(order.Items)
+}
+
+func orderItemsFromLegacy(dishIDs []int64) []*pbOrders.OrderItem {
+	items := make([]*pbOrders.OrderItem, 0, len(dishIDs))
+	index := make(map[int64]int, len(dishIDs))
+	for _, dishID := range dishIDs {
+		if i, ok := index[dishID]; ok {
+			items[i].Quantity++
+			continue
+		}
+		index[dishID] = len(items)
+		items = append(items, &pbOrders.OrderItem{DishId: dishID, Quantity: 1})
+	}
+	return items
+}
+
+// flattenOrderItems переводит позиции в устаревший формат items.
+func flattenOrderItems(items []*pbOrders.OrderItem) []int64 {
+	var dishIDs []int64
+	for _, item := range items {
+		for i := int32(0); i < item.Quantity; i++ {
+			dishIDs = append(dishIDs, item.DishId)
+		}
+	}
+	return dishIDs
+}
+
+func toOrderItemResponse(item *pbOrders.OrderItem) OrderItemResponse {
+	modifiers := item.Modifiers
+	if modifiers == nil {
+		modifiers = []string{}
+	}
+	return OrderItemResponse{
+		DishID:    item.DishId,
+		Quantity:  item.Quantity,
+		Notes:     item.Notes,
+		Modifiers: modifiers,
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anyviewww/bff-service/internal/order"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// transitionOrder переводит заказ в статус target после проверки перехода
+// относительно текущего статуса в OrderService.
+func (h *Handler) transitionOrder(c *gin.Context, target string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, codes.InvalidArgument, "Invalid order ID format")
+		return
+	}
+
+	current, ok := h.checkOrderTransition(c, id, target)
+	if !ok {
+		return
+	}
+	if order.NormalizeStatus(current.Status) == target {
+		c.JSON(http.StatusOK, h.orderResponse(c.Request.Context(), current))
+		return
+	}
+
+	updated, err := h.orderClient.UpdateOrder(c.Request.Context(), &pbOrders.UpdateOrderRequest{
+		Id:     id,
+		Status: target,
+	})
+	if err != nil {
+		respondGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.orderResponse(c.Request.Context(), updated))
+}
+
+func (h *Handler) ConfirmOrder(c *gin.Context) { h.transitionOrder(c, order.StatusConfirmed) }
+
+func (h *Handler) StartCookingOrder(c *gin.Context) { h.transitionOrder(c, order.StatusCooking) }
+
+func (h *Handler) MarkOrderReady(c *gin.Context) { h.transitionOrder(c, order.StatusReady) }
+
+func (h *Handler) DeliverOrder(c *gin.Context) { h.transitionOrder(c, order.StatusDelivered) }
+
+func (h *Handler) CancelOrder(c *gin.Context) { h.transitionOrder(c, order.StatusCancelled) }
+
+// checkOrderTransition загружает текущий заказ и проверяет переход в target.
+// При ошибке ответ уже отправлен и возвращается false.
+func (h *Handler) checkOrderTransition(c *gin.Context, id uint64, target string) (*pbOrders.OrderResponse, bool) {
+	current, ok := h.loadOwnedOrder(c, id)
+	if !ok {
+		return nil, false
+	}
+
+	if err := order.CheckTransition(current.Status, target); err != nil {
+		respondTransitionError(c, err)
+		return nil, false
+	}
+
+	return current, true
+}
+
+// respondTransitionError отвечает 409 на недопустимый переход статуса.
+// Остальные FailedPrecondition, в том числе от бэкендов, отдаются с кодом 400.
+func respondTransitionError(c *gin.Context, err error) {
+	respondHTTPError(c, http.StatusConflict, mappingFor(codes.FailedPrecondition).name, err.Error())
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/anyviewww/bff-service/internal/pricing"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+)
+
+// WithPricing добавляет в ответы с заказами расчёт стоимости.
+func WithPricing(calc *pricing.Calculator) Option {
+	return func(h *Handler) {
+		h.pricing = calc
+	}
+}
+
+// orderResponses переводит заказы в ответы и, если включён расчёт стоимости,
+// добавляет к каждому поле pricing. Блюда всех заказов запрашиваются в
+// DishService одним набором запросов.
+func (h *Handler) orderResponses(ctx context.Context, orders ...*pbOrders.OrderResponse) []OrderResponse {
+	responses := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toOrderResponse(order))
+	}
+	if h.pricing == nil || len(orders) == 0 {
+		return responses
+	}
+
+	var dishIDs []int64
+	seen := make(map[int64]bool)
+	for _, order := range orders {
+		for _, item := range orderItemsOf(order) {
+			if !seen[item.DishId] {
+				seen[item.DishId] = true
+				dishIDs = append(dishIDs, item.DishId)
+			}
+		}
+	}
+	lookups := h.lookupDishes(ctx, dishIDs)
+
+	for i, order := range orders {
+		responses[i].Pricing = h.priceOrder(ctx, orderItemsOf(order), lookups)
+	}
+	return responses
+}
+
+func (h *Handler) orderResponse(ctx context.Context, order *pbOrders.OrderResponse) OrderResponse {
+	return h.orderResponses(ctx, order)[0]
+}
+
+// priceOrder считает стоимость позиций. Ошибка расчёта не прерывает запрос:
+// заказ отдаётся с pricing.complete = false и описанием ошибки.
+func (h *Handler) priceOrder(ctx context.Context, items []*pbOrders.OrderItem, lookups map[int64]dishLookup) *PricingResponse {
+	priceItems := make([]pricing.Item, 0, len(items))
+	for _, item := range items {
+		pi := pricing.Item{DishID: item.DishId, Quantity: item.Quantity}
+		// price в DishService без признака наличия: 0 означает, что цена
+		// не задана, а не бесплатное блюдо
+		if dish := lookups[item.DishId].dish; dish != nil && dish.GetPrice() != 0 {
+			pi.Priced = true
+			pi.UnitPrice = dish.GetPrice()
+			pi.Currency = dish.GetCurrency()
+			pi.CategoryID = dish.GetCategory().GetId()
+		}
+		priceItems = append(priceItems, pi)
+	}
+
+	bill, err := h.pricing.Price(priceItems)
+	if err != nil {
+		if !errors.Is(err, pricing.ErrMixedCurrencies) {
+			slog.ErrorContext(ctx, "failed to price order", slog.Any("error", err))
+		}
+		return &PricingResponse{Complete: false, Error: err.Error()}
+	}
+	return toPricingResponse(bill)
+}
+
+func toPricingResponse(bill *pricing.Bill) *PricingResponse {
+	lines := make([]PricingLine, 0, len(bill.Lines))
+	for _, line := range bill.Lines {
+		lines = append(lines, PricingLine{
+			DishID:    line.DishID,
+			Quantity:  line.Quantity,
+			UnitPrice: toMoneyResponse(line.UnitPrice),
+			LineTotal: toMoneyResponse(line.Total),
+			TaxRate:   line.TaxRate.String(),
+		})
+	}
+
+	taxes := make([]TaxLine, 0, len(bill.Taxes))
+	for _, tax := range bill.Taxes {
+		taxes = append(taxes, TaxLine{
+			Rate:    tax.Rate.String(),
+			Taxable: toMoneyResponse(tax.Taxable),
+			Tax:     toMoneyResponse(tax.Tax),
+		})
+	}
+
+	return &PricingResponse{
+		Complete: bill.Complete(),
+		BillResponse: &BillResponse{
+			Currency:        bill.Currency,
+			Lines:           lines,
+			Subtotal:        toMoneyResponse(bill.Subtotal),
+			Taxes:           taxes,
+			Tax:             toMoneyResponse(bill.Tax),
+			Total:           toMoneyResponse(bill.Total),
+			UnpricedDishIDs: bill.Unpriced,
+		},
+	}
+}
+
+// toMoneyResponse отдаёт сумму и в минимальных единицах для расчётов, и
+// строкой для отображения.
+func toMoneyResponse(m pricing.Money) MoneyResponse {
+	return MoneyResponse{
+		AmountMinor: m.Minor,
+		Amount:      m.String(),
+		Currency:    m.Currency,
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"crypto/sha256"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// Группы маршрутов с отдельными лимитами
+const (
+	rateLimitMenu   = "menu"
+	rateLimitOrders = "orders"
+)
+
+type RateLimitOptions struct {
+	Menu ratelimit.Limit
+	// Orders действует на заказы, корзину и историю заказов пользователя
+	Orders ratelimit.Limit
+	// APIKeys - ключи клиентов для заголовка X-API-Key по id клиента
+	APIKeys map[string]string
+}
+
+type rateLimiter struct {
+	store  ratelimit.Store
+	limits map[string]ratelimit.Limit
+	// apiKeys - id клиентов по хешу ключа. Поиск по хешу не выдаёт по
+	// времени ответа, сколько символов ключа совпало.
+	apiKeys map[[sha256.Size]byte]string
+}
+
+// WithRateLimit включает ограничение частоты запросов к группам маршрутов.
+func WithRateLimit(store ratelimit.Store, opts RateLimitOptions) Option {
+	return func(h *Handler) {
+		rl := &rateLimiter{
+			store: store,
+			limits: map[string]ratelimit.Limit{
+				rateLimitMenu:   opts.Menu,
+				rateLimitOrders: opts.Orders,
+			},
+			apiKeys: make(map[[sha256.Size]byte]string, len(opts.APIKeys)),
+		}
+		for id, key := range opts.APIKeys {
+			rl.apiKeys[sha256.Sum256([]byte(key))] = id
+		}
+		h.rateLimiter = rl
+	}
+}
+
+// RateLimit ограничивает частоту запросов к группе маршрутов group. Лимит
+// считается отдельно для каждого пользователя, клиента с известным API-ключом
+// или, для остальных, IP-адреса. Должен стоять после Authenticate.
+func (h *Handler) RateLimit(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.rateLimiter == nil {
+			c.Next()
+			return
+		}
+
+		limit := h.rateLimiter.limits[group]
+		res, err := h.rateLimiter.store.Take(c.Request.Context(), group+":"+h.rateLimiter.key(c), limit)
+		if err != nil {
+			// Недоступность хранилища лимитов не должна останавливать сервис
+			slog.WarnContext(c.Request.Context(), "rate limit store failed", slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+
+		if !res.Allowed {
+			header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			respondError(c, codes.ResourceExhausted, "Too many requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+// key различает клиентов только по проверенным данным. Неизвестный
+// X-API-Key не учитывается: иначе новый ключ в каждом запросе давал бы
+// новый лимит.
+func (rl *rateLimiter) key(c *gin.Context) string {
+	if p, ok := principal(c); ok {
+		return "user:" + strconv.FormatUint(p.UserID, 10)
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if id, ok := rl.apiKeys[sha256.Sum256([]byte(key))]; ok {
+			return "key:" + id
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds округляет вверх до целых секунд, как требуют Retry-After и
+// RateLimit-Reset.
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/health"
+	"github.com/anyviewww/bff-service/internal/openapi"
+)
+
+// Типы ответов API. По ним же строится схема OpenAPI, поэтому теги json
+// определяют и формат ответа, и обязательность полей в спецификации.
+
+type DishResponse struct {
+	ID        int32                `json:"id"`
+	Name      string               `json:"name"`
+	Type      DishTypeResponse     `json:"type"`
+	Category  DishCategoryResponse `json:"category"`
+	Nutrition NutritionResponse    `json:"nutrition"`
+	Tag       DishTagResponse      `json:"tag"`
+	Recipe    string               `json:"recipe"`
+	// Price отсутствует, если цена блюда не задана
+	Price *MoneyResponse `json:"price,omitempty"`
+}
+
+type DishTypeResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type DishCategoryResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type DishTagResponse struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type NutritionResponse struct {
+	Calories      float32 `json:"calories"`
+	Proteins      float32 `json:"proteins"`
+	Fats          float32 `json:"fats"`
+	Carbohydrates float32 `json:"carbohydrates"`
+}
+
+type DishListResponse struct {
+	Dishes []DishResponse `json:"dishes"`
+	// NextCursor - курсор следующей страницы, null на последней
+	NextCursor *string `json:"next_cursor"`
+}
+
+type OrderResponse struct {
+	ID     uint64 `json:"id"`
+	UserID uint64 `json:"user_id"`
+	// Items - устаревший формат позиций: id блюда повторяется по количеству
+	Items      []int64             `json:"items"`
+	OrderItems []OrderItemResponse `json:"order_items"`
+	Status     string              `json:"status"`
+	// Pricing есть, если включён расчёт стоимости
+	Pricing *PricingResponse `json:"pricing,omitempty"`
+}
+
+type OrderItemResponse struct {
+	DishID    int64    `json:"dish_id"`
+	Quantity  int32    `json:"quantity"`
+	Notes     string   `json:"notes"`
+	Modifiers []string `json:"modifiers"`
+}
+
+type OrderListResponse struct {
+	Orders []OrderResponse `json:"orders"`
+	// NextPageToken - токен следующей страницы, null на последней
+	NextPageToken *string `json:"next_page_token"`
+}
+
+type OrderDetailsResponse struct {
+	ID              uint64                  `json:"id"`
+	UserID          uint64                  `json:"user_id"`
+	Status          string                  `json:"status"`
+	Items           []OrderDetailsItem      `json:"items"`
+	NutritionTotals NutritionTotalsResponse `json:"nutrition_totals"`
+	// Partial - часть блюд не удалось получить из DishService
+	Partial           bool             `json:"partial"`
+	UnresolvedDishIDs []int64          `json:"unresolved_dish_ids"`
+	Pricing           *PricingResponse `json:"pricing,omitempty"`
+}
+
+// OrderDetailsItem - позиция заказа с блюдом. Если блюдо не получено, dish
+// равно null, а error содержит причину.
+type OrderDetailsItem struct {
+	OrderItemResponse
+	Resolved bool          `json:"resolved"`
+	Dish     *DishResponse `json:"dish"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type NutritionTotalsResponse struct {
+	Calories      float64 `json:"calories"`
+	Proteins      float64 `json:"proteins"`
+	Fats          float64 `json:"fats"`
+	Carbohydrates float64 `json:"carbohydrates"`
+}
+
+// PricingResponse - расчёт стоимости заказа. Если посчитать не удалось,
+// complete равно false, error содержит причину, а остальных полей нет.
+type PricingResponse struct {
+	Complete bool   `json:"complete"`
+	Error    string `json:"error,omitempty"`
+	*BillResponse
+}
+
+type BillResponse struct {
+	Currency string        `json:"currency"`
+	Lines    []PricingLine `json:"lines"`
+	Subtotal MoneyResponse `json:"subtotal"`
+	Taxes    []TaxLine     `json:"taxes"`
+	Tax      MoneyResponse `json:"tax"`
+	Total    MoneyResponse `json:"total"`
+	// UnpricedDishIDs - блюда без цены, суммы посчитаны без них
+	UnpricedDishIDs []int64 `json:"unpriced_dish_ids"`
+}
+
+type PricingLine struct {
+	DishID    int64         `json:"dish_id"`
+	Quantity  int32         `json:"quantity"`
+	UnitPrice MoneyResponse `json:"unit_price"`
+	LineTotal MoneyResponse `json:"line_total"`
+	// TaxRate - ставка в процентах, например "20"
+	TaxRate string `json:"tax_rate"`
+}
+
+type TaxLine struct {
+	Rate    string        `json:"rate"`
+	Taxable MoneyResponse `json:"taxable"`
+	Tax     MoneyResponse `json:"tax"`
+}
+
+// MoneyResponse - сумма в минимальных единицах валюты для расчётов и
+// строкой для отображения.
+type MoneyResponse struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency"`
+}
+
+type CartResponse struct {
+	UserID        uint64             `json:"user_id"`
+	Items         []CartItemResponse `json:"items"`
+	TotalQuantity int                `json:"total_quantity"`
+	// UpdatedAt - время последнего изменения, null для пустой корзины
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type CartItemResponse struct {
+	DishID   int64 `json:"dish_id"`
+	Quantity int   `json:"quantity"`
+}
+
+// OrderEventResponse - изменение заказа в потоке событий. ID - номер
+// события для возобновления через Last-Event-ID.
+type OrderEventResponse struct {
+	ID         string        `json:"id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Order      OrderResponse `json:"order"`
+}
+
+// OrderStreamMessage - сообщение WebSocket. Для type = order поля события
+// находятся на верхнем уровне, для end и error - в data.
+type OrderStreamMessage struct {
+	Type string `json:"type" openapi:"enum=order|end|error"`
+	*OrderEventResponse
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// StreamEndResponse - данные события end: заказ завершён и изменений
+// больше не будет.
+type StreamEndResponse struct {
+	Reason string `json:"reason"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type HealthResponse struct {
+	Status  string         `json:"status" openapi:"enum=ok|ready|not_ready"`
+	Details *health.Report `json:"details,omitempty"`
+}
+
+// ErrorResponse - единый формат ошибок API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	// Code - имя кода gRPC, например NOT_FOUND
+	Code      string        `json:"code"`
+	Message   string        `json:"message"`
+	RequestID string        `json:"request_id"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	// Fields - ошибки проверки запроса по полям
+	Fields []openapi.FieldError `json:"fields,omitempty"`
+}
+
+// ErrorDetail - деталь статуса gRPC в формате protojson.
+type ErrorDetail struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
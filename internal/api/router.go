@@ -13,26 +13,76 @@ func NewRouter(handler *Handler) *Router {
 }
 
 func (r *Router) SetupRoutes(engine *gin.Engine) {
-	api := engine.Group("/api/v1")
+	// Лимит частоты проверяется до ValidateRequest, чтобы не читать тело
+	// запросов сверх лимита
+	api := engine.Group("/api/v1", r.handler.Authenticate)
 	{
 		// Menu endpoints
-		menu := api.Group("/menu")
+		menu := api.Group("/menu", r.handler.RateLimit(rateLimitMenu), r.handler.ValidateRequest)
 		{
+			menu.GET("/dishes", r.handler.GetAllDishes)
 			menu.GET("/dishes/:id", r.handler.GetDish)
 		}
 
 		// Order endpoints
-		orders := api.Group("/orders")
+		orders := api.Group("/orders", r.handler.RateLimit(rateLimitOrders), r.handler.ValidateRequest)
 		{
 			orders.POST("/", r.handler.CreateOrder)
 			orders.GET("/:id", r.handler.GetOrder)
+			orders.GET("/:id/details", r.handler.GetOrderDetails)
+			orders.GET("/:id/events", r.handler.OrderEventsSSE)
+			orders.GET("/:id/events/ws", r.handler.OrderEventsWebSocket)
 			orders.PUT("/:id", r.handler.UpdateOrder)
 			orders.DELETE("/:id", r.handler.DeleteOrder)
+
+			// Status actions
+			orders.POST("/:id/confirm", r.handler.ConfirmOrder)
+			orders.POST("/:id/cook", r.handler.StartCookingOrder)
+			orders.POST("/:id/ready", r.handler.MarkOrderReady)
+			orders.POST("/:id/deliver", r.handler.DeliverOrder)
+			orders.POST("/:id/cancel", r.handler.CancelOrder)
+		}
+
+		// Cart endpoints
+		cart := api.Group("/cart", r.handler.RateLimit(rateLimitOrders), r.handler.ValidateRequest)
+		{
+			cart.GET("", r.handler.GetCart)
+			cart.DELETE("", r.handler.ClearCart)
+			cart.POST("/items", r.handler.AddCartItem)
+			cart.PUT("/items/:dish_id", r.handler.SetCartItemQuantity)
+			cart.DELETE("/items/:dish_id", r.handler.RemoveCartItem)
+			cart.POST("/checkout", r.handler.CheckoutCart)
+		}
+
+		// Admin endpoints
+		admin := api.Group("/admin", r.handler.RequireAdmin, r.handler.ValidateRequest)
+		{
+			admin.POST("/cache/menu/purge", r.handler.PurgeMenuCache)
+		}
+
+		// User endpoints
+		users := api.Group("/users", r.handler.RateLimit(rateLimitOrders), r.handler.ValidateRequest)
+		{
+			users.GET("/:user_id/orders", r.handler.GetUserOrders)
 		}
 	}
 
-	// Health check
-	engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// GraphQL
+	graphql := []gin.HandlerFunc{
+		r.handler.Authenticate,
+		r.handler.RateLimit(rateLimitOrders),
+		r.handler.ValidateRequest,
+		r.handler.GraphQL,
+	}
+	engine.GET("/graphql", graphql...)
+	engine.POST("/graphql", graphql...)
+
+	// Health checks
+	engine.GET("/livez", r.handler.Livez)
+	engine.GET("/readyz", r.handler.Readyz)
+	engine.GET("/health", r.handler.Livez)
+
+	// API documentation
+	engine.GET("/openapi.json", r.handler.OpenAPISpec)
+	engine.GET("/docs", r.handler.APIDocs)
 }
@@ -0,0 +1,670 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/api"
+	"github.com/anyviewww/bff-service/internal/ratelimit"
+	"github.com/anyviewww/bff-service/internal/testing/fakes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	for _, path := range []string{"/livez", "/health"} {
+		body := expectStatus(t, env.do(http.MethodGet, path, nil), http.StatusOK)
+		if body["status"] != "ok" {
+			t.Errorf("%s status = %v, want ok", path, body["status"])
+		}
+	}
+
+	body := expectStatus(t, env.do(http.MethodGet, "/readyz", nil), http.StatusOK)
+	if body["status"] != "ready" {
+		t.Errorf("readyz status = %v, want ready", body["status"])
+	}
+}
+
+func TestGetAllDishes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	rec := env.do(http.MethodGet, "/api/v1/menu/dishes", nil)
+	body := expectStatus(t, rec, http.StatusOK)
+	if got := ids(t, body["dishes"]); len(got) != 4 {
+		t.Fatalf("dishes = %v, want 4 dishes", got)
+	}
+	if body["next_cursor"] != nil {
+		t.Errorf("next_cursor = %v, want null", body["next_cursor"])
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("response has no ETag")
+	}
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil, "If-None-Match", etag), http.StatusNotModified)
+
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes?category_id=1&sort=-calories&limit=1", nil), http.StatusOK)
+	if got := ids(t, body["dishes"]); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("first page = %v, want [2]", got)
+	}
+	cursor, _ := body["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatal("next_cursor is empty")
+	}
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes?category_id=1&sort=-calories&limit=1&cursor="+cursor, nil), http.StatusOK)
+	if got := ids(t, body["dishes"]); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("second page = %v, want [1]", got)
+	}
+
+	// Курсор привязан к фильтрам и сортировке, но не к limit
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes?sort=-calories&category_id=1&limit=2&cursor="+cursor, nil), http.StatusOK)
+	for _, query := range []string{"category_id=1&sort=calories", "category_id=2&sort=-calories", "sort=-calories"} {
+		expectError(t, env.do(http.MethodGet, "/api/v1/menu/dishes?"+query+"&cursor="+cursor, nil),
+			http.StatusBadRequest, "INVALID_ARGUMENT")
+	}
+
+	expectError(t, env.do(http.MethodGet, "/api/v1/menu/dishes?limit=0", nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+}
+
+func TestGetAllDishesBackendFaults(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	tests := []struct {
+		name    string
+		fault   fakes.Fault
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:   "unavailable",
+			fault:  fakes.Fault{Code: codes.Unavailable, Message: "menu is down"},
+			status: http.StatusServiceUnavailable,
+			code:   "UNAVAILABLE",
+		},
+		{
+			name:   "slow backend",
+			fault:  fakes.Fault{Delay: 2 * testCallTimeout},
+			status: http.StatusGatewayTimeout,
+			code:   "DEADLINE_EXCEEDED",
+		},
+		{
+			name:    "internal error is hidden",
+			fault:   fakes.Fault{Code: codes.Internal, Message: "connection to db 10.0.0.1 refused"},
+			status:  http.StatusInternalServerError,
+			code:    "INTERNAL",
+			message: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.backend.Dishes.Fail("GetDishes", tt.fault)
+			defer env.backend.Dishes.ClearFaults()
+
+			apiErr := expectError(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil), tt.status, tt.code)
+			if tt.message != "" && apiErr["message"] != tt.message {
+				t.Errorf("message = %v, want %q", apiErr["message"], tt.message)
+			}
+		})
+	}
+
+	// Ошибка на одну попытку скрывается повтором идемпотентного вызова
+	env.backend.Dishes.ResetCalls()
+	env.backend.Dishes.Fail("GetDishes", fakes.Fault{Code: codes.Unavailable, Times: 1})
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil), http.StatusOK)
+	if calls := env.backend.Dishes.Calls("GetDishes"); len(calls) != 2 {
+		t.Errorf("GetDishes calls = %d, want 2", len(calls))
+	}
+}
+
+func TestGetDish(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	body := expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes/1", nil, "X-Request-ID", "test-request-1"), http.StatusOK)
+	if body["name"] != "Борщ" {
+		t.Errorf("name = %v, want Борщ", body["name"])
+	}
+	price := body["price"].(map[string]interface{})
+	if price["amount"] != "350.00" || price["currency"] != "RUB" {
+		t.Errorf("price = %v, want 350.00 RUB", price)
+	}
+
+	calls := env.backend.Dishes.Calls("GetDishes")
+	if len(calls) != 1 {
+		t.Fatalf("GetDishes calls = %d, want 1", len(calls))
+	}
+	if got := calls[0].Metadata.Get("x-request-id"); len(got) != 1 || got[0] != "test-request-1" {
+		t.Errorf("x-request-id metadata = %v, want test-request-1", got)
+	}
+
+	expectError(t, env.do(http.MethodGet, "/api/v1/menu/dishes/99", nil), http.StatusNotFound, "NOT_FOUND")
+	expectError(t, env.do(http.MethodGet, "/api/v1/menu/dishes/abc", nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+}
+
+func TestGetDishPrice(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	// Без валюты цена отдаётся в валюте расчёта по умолчанию
+	body := expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes/3", nil), http.StatusOK)
+	price := body["price"].(map[string]interface{})
+	if price["amount"] != "120.00" || price["currency"] != "RUB" {
+		t.Errorf("price = %v, want 120.00 RUB", price)
+	}
+
+	// Нулевая цена означает, что цена не задана
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes/4", nil), http.StatusOK)
+	if price, ok := body["price"]; ok {
+		t.Errorf("price = %v, want no price", price)
+	}
+}
+
+func TestPurgeMenuCache(t *testing.T) {
+	t.Run("cache disabled", func(t *testing.T) {
+		env := newTestEnv(t, envConfig{anonymousAdmin: true})
+		expectError(t, env.do(http.MethodPost, "/api/v1/admin/cache/menu/purge", nil), http.StatusNotImplemented, "UNIMPLEMENTED")
+	})
+
+	t.Run("auth disabled", func(t *testing.T) {
+		env := newTestEnv(t, envConfig{menuCache: true})
+		expectError(t, env.do(http.MethodPost, "/api/v1/admin/cache/menu/purge", nil), http.StatusForbidden, "PERMISSION_DENIED")
+	})
+
+	t.Run("cache enabled", func(t *testing.T) {
+		env := newTestEnv(t, envConfig{menuCache: true, auth: true})
+		admin := bearer(t, 1, testAdminRole)
+
+		expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil, "Authorization", admin), http.StatusOK)
+		expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil, "Authorization", admin), http.StatusOK)
+		if calls := env.backend.Dishes.Calls("GetDishes"); len(calls) != 1 {
+			t.Fatalf("GetDishes calls before purge = %d, want 1", len(calls))
+		}
+
+		expectError(t, env.do(http.MethodPost, "/api/v1/admin/cache/menu/purge", nil, "Authorization", bearer(t, 2)),
+			http.StatusForbidden, "PERMISSION_DENIED")
+		expectStatus(t, env.do(http.MethodPost, "/api/v1/admin/cache/menu/purge", nil, "Authorization", admin), http.StatusOK)
+
+		expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil, "Authorization", admin), http.StatusOK)
+		if calls := env.backend.Dishes.Calls("GetDishes"); len(calls) != 2 {
+			t.Errorf("GetDishes calls after purge = %d, want 2", len(calls))
+		}
+	})
+}
+
+func TestCreateOrder(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	body := expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{
+		"user_id": 7,
+		"items":   []int64{1, 1, 3},
+	}), http.StatusCreated)
+	if body["status"] != "created" || body["user_id"] != float64(7) {
+		t.Errorf("order = %v", body)
+	}
+
+	// 2 x 350.00 по 20% и 120.00 по 10%
+	pricing := body["pricing"].(map[string]interface{})
+	total := pricing["total"].(map[string]interface{})
+	if total["amount"] != "972.00" {
+		t.Errorf("total = %v, want 972.00", total["amount"])
+	}
+
+	calls := env.backend.Orders.Calls("CreateOrder")
+	if len(calls) != 1 {
+		t.Fatalf("CreateOrder calls = %d, want 1", len(calls))
+	}
+	req := calls[0].Request.(*pbOrders.CreateOrderRequest)
+	if req.UserId != 7 || len(req.OrderItems) != 2 || req.OrderItems[0].Quantity != 2 {
+		t.Errorf("CreateOrder request = %v", req)
+	}
+
+	body = expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{
+		"user_id": 7,
+		"order_items": []map[string]interface{}{
+			{"dish_id": 2, "quantity": 3, "notes": "без сметаны", "modifiers": []string{"острый"}},
+		},
+	}), http.StatusCreated)
+	items := body["order_items"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["notes"] != "без сметаны" {
+		t.Errorf("order_items = %v", items)
+	}
+
+	invalid := []map[string]interface{}{
+		{"user_id": 7},
+		{"items": []int64{1}},
+		{"user_id": 7, "items": []int64{1}, "order_items": []map[string]interface{}{{"dish_id": 1}}},
+		{"user_id": 7, "order_items": []map[string]interface{}{{"dish_id": 1, "quantity": -1}}},
+	}
+	for _, req := range invalid {
+		expectError(t, env.do(http.MethodPost, "/api/v1/orders/", req), http.StatusBadRequest, "INVALID_ARGUMENT")
+	}
+
+	env.backend.Orders.Fail("CreateOrder", fakes.Fault{Code: codes.FailedPrecondition, Message: "kitchen is closed", Times: 1})
+	apiErr := expectError(t, env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{"user_id": 7, "items": []int64{1}}),
+		http.StatusBadRequest, "FAILED_PRECONDITION")
+	if apiErr["message"] != "kitchen is closed" {
+		t.Errorf("message = %v, want backend message", apiErr["message"])
+	}
+}
+
+func TestCreateOrderIdempotency(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	req := map[string]interface{}{"user_id": 7, "items": []int64{1}}
+
+	// Неудачный запрос не занимает ключ
+	env.backend.Orders.Fail("CreateOrder", fakes.Fault{Code: codes.Unavailable, Times: 1})
+	expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", req, "Idempotency-Key", "order-1"), http.StatusServiceUnavailable)
+	env.backend.Orders.ResetCalls()
+
+	first := expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", req, "Idempotency-Key", "order-1"), http.StatusCreated)
+
+	rec := env.do(http.MethodPost, "/api/v1/orders/", req, "Idempotency-Key", "order-1")
+	second := expectStatus(t, rec, http.StatusCreated)
+	if second["id"] != first["id"] {
+		t.Errorf("replayed order id = %v, want %v", second["id"], first["id"])
+	}
+	if rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response has no Idempotent-Replayed header")
+	}
+	if calls := env.backend.Orders.Calls("CreateOrder"); len(calls) != 1 {
+		t.Errorf("CreateOrder calls = %d, want 1", len(calls))
+	}
+
+	other := map[string]interface{}{"user_id": 7, "items": []int64{2}}
+	expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", other, "Idempotency-Key", "order-1"), http.StatusUnprocessableEntity)
+}
+
+func TestOrderRoutes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	seeded := env.backend.Orders.Seed(&pbOrders.OrderResponse{
+		UserId:     5,
+		Status:     "created",
+		OrderItems: []*pbOrders.OrderItem{{DishId: 1, Quantity: 2}, {DishId: 4, Quantity: 1}},
+	})
+	id := seeded[0].Id
+
+	body := expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/1", nil), http.StatusOK)
+	if body["id"] != float64(id) || body["user_id"] != float64(5) {
+		t.Errorf("order = %v", body)
+	}
+	// Блюдо 4 без цены не входит в сумму
+	pricing := body["pricing"].(map[string]interface{})
+	if total := pricing["total"].(map[string]interface{}); total["amount"] != "840.00" {
+		t.Errorf("total = %v, want 840.00", total["amount"])
+	}
+	if unpriced := pricing["unpriced_dish_ids"].([]interface{}); pricing["complete"] != false || len(unpriced) != 1 || unpriced[0] != float64(4) {
+		t.Errorf("pricing = %v, want incomplete without dish 4", pricing)
+	}
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/999", nil), http.StatusNotFound, "NOT_FOUND")
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/abc", nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/1/details", nil), http.StatusOK)
+	nutrition := body["nutrition_totals"].(map[string]interface{})
+	if nutrition["calories"] != float64(2*250+600) {
+		t.Errorf("calories = %v, want 1100", nutrition["calories"])
+	}
+	if body["partial"] != false {
+		t.Errorf("partial = %v, want false", body["partial"])
+	}
+
+	env.backend.Dishes.RemoveDish(4)
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/1/details", nil), http.StatusOK)
+	if body["partial"] != true {
+		t.Errorf("partial after dish removal = %v, want true", body["partial"])
+	}
+
+	body = expectStatus(t, env.do(http.MethodPut, "/api/v1/orders/1", map[string]interface{}{
+		"order_items": []map[string]interface{}{{"dish_id": 2, "quantity": 1}},
+	}), http.StatusOK)
+	if items := body["items"].([]interface{}); len(items) != 1 || items[0] != float64(2) {
+		t.Errorf("items after update = %v, want [2]", items)
+	}
+
+	expectError(t, env.do(http.MethodPut, "/api/v1/orders/1", map[string]interface{}{"status": "flying"}),
+		http.StatusBadRequest, "INVALID_ARGUMENT")
+	expectError(t, env.do(http.MethodPut, "/api/v1/orders/1", map[string]interface{}{"status": "ready"}),
+		http.StatusConflict, "FAILED_PRECONDITION")
+	body = expectStatus(t, env.do(http.MethodPut, "/api/v1/orders/1", map[string]interface{}{"status": "confirmed"}), http.StatusOK)
+	if body["status"] != "confirmed" {
+		t.Errorf("status = %v, want confirmed", body["status"])
+	}
+
+	expectStatus(t, env.do(http.MethodDelete, "/api/v1/orders/1", nil), http.StatusOK)
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/1", nil), http.StatusNotFound, "NOT_FOUND")
+	expectError(t, env.do(http.MethodDelete, "/api/v1/orders/1", nil), http.StatusNotFound, "NOT_FOUND")
+}
+
+func TestOrderStatusActions(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}})
+
+	steps := []struct {
+		action string
+		status string
+	}{
+		{"confirm", "confirmed"},
+		{"cook", "cooking"},
+		{"ready", "ready"},
+		{"deliver", "delivered"},
+	}
+	for _, step := range steps {
+		body := expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/1/"+step.action, nil), http.StatusOK)
+		if body["status"] != step.status {
+			t.Fatalf("%s: status = %v, want %s", step.action, body["status"], step.status)
+		}
+	}
+
+	// Повтор перехода в текущий статус не обращается к OrderService
+	env.backend.Orders.ResetCalls()
+	expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/1/deliver", nil), http.StatusOK)
+	if calls := env.backend.Orders.Calls("UpdateOrder"); len(calls) != 0 {
+		t.Errorf("UpdateOrder calls = %d, want 0", len(calls))
+	}
+
+	expectError(t, env.do(http.MethodPost, "/api/v1/orders/1/cancel", nil), http.StatusConflict, "FAILED_PRECONDITION")
+
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 2, UserId: 5, Items: []int64{1}, Status: "new"})
+	body := expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/2/cancel", nil), http.StatusOK)
+	if body["status"] != "cancelled" {
+		t.Errorf("status = %v, want cancelled", body["status"])
+	}
+}
+
+func TestGetUserOrders(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(
+		&pbOrders.OrderResponse{UserId: 5, Items: []int64{1}, Status: "created"},
+		&pbOrders.OrderResponse{UserId: 6, Items: []int64{1}, Status: "created"},
+		&pbOrders.OrderResponse{UserId: 5, Items: []int64{2}, Status: "delivered"},
+		&pbOrders.OrderResponse{UserId: 5, Items: []int64{3}, Status: "created"},
+	)
+
+	body := expectStatus(t, env.do(http.MethodGet, "/api/v1/users/5/orders?page_size=2", nil), http.StatusOK)
+	if got := ids(t, body["orders"]); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("first page = %v, want [1 3]", got)
+	}
+	token, _ := body["next_page_token"].(string)
+	if token == "" {
+		t.Fatal("next_page_token is empty")
+	}
+
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/users/5/orders?page_size=2&page_token="+token, nil), http.StatusOK)
+	if got := ids(t, body["orders"]); len(got) != 1 || got[0] != 4 {
+		t.Fatalf("second page = %v, want [4]", got)
+	}
+	if body["next_page_token"] != nil {
+		t.Errorf("next_page_token = %v, want null", body["next_page_token"])
+	}
+
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/users/5/orders?status=delivered", nil), http.StatusOK)
+	if got := ids(t, body["orders"]); len(got) != 1 || got[0] != 3 {
+		t.Errorf("delivered orders = %v, want [3]", got)
+	}
+
+	expectError(t, env.do(http.MethodGet, "/api/v1/users/5/orders?page_size=1000", nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+	expectError(t, env.do(http.MethodGet, "/api/v1/users/x/orders", nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+}
+
+func TestCartRoutes(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	const cart = "/api/v1/cart"
+	const user = "?user_id=9"
+
+	expectError(t, env.do(http.MethodGet, cart, nil), http.StatusBadRequest, "INVALID_ARGUMENT")
+
+	body := expectStatus(t, env.do(http.MethodGet, cart+user, nil), http.StatusOK)
+	if body["total_quantity"] != float64(0) {
+		t.Fatalf("new cart = %v", body)
+	}
+
+	expectStatus(t, env.do(http.MethodPost, cart+"/items"+user, map[string]interface{}{"dish_id": 1, "quantity": 2}), http.StatusOK)
+	expectStatus(t, env.do(http.MethodPost, cart+"/items"+user, map[string]interface{}{"dish_id": 3}), http.StatusOK)
+	expectError(t, env.do(http.MethodPost, cart+"/items"+user, map[string]interface{}{"dish_id": 99}),
+		http.StatusBadRequest, "INVALID_ARGUMENT")
+
+	body = expectStatus(t, env.do(http.MethodPut, cart+"/items/1"+user, map[string]interface{}{"quantity": 4}), http.StatusOK)
+	if body["total_quantity"] != float64(5) {
+		t.Errorf("total_quantity = %v, want 5", body["total_quantity"])
+	}
+
+	body = expectStatus(t, env.do(http.MethodDelete, cart+"/items/3"+user, nil), http.StatusOK)
+	if body["total_quantity"] != float64(4) {
+		t.Errorf("total_quantity after removal = %v, want 4", body["total_quantity"])
+	}
+	expectError(t, env.do(http.MethodDelete, cart+"/items/3"+user, nil), http.StatusNotFound, "NOT_FOUND")
+
+	body = expectStatus(t, env.do(http.MethodPost, cart+"/checkout"+user, nil), http.StatusCreated)
+	items := body["order_items"].([]interface{})
+	if body["user_id"] != float64(9) || len(items) != 1 || items[0].(map[string]interface{})["quantity"] != float64(4) {
+		t.Errorf("checkout order = %v", body)
+	}
+
+	body = expectStatus(t, env.do(http.MethodGet, cart+user, nil), http.StatusOK)
+	if body["total_quantity"] != float64(0) {
+		t.Errorf("cart after checkout = %v, want empty", body)
+	}
+	expectError(t, env.do(http.MethodPost, cart+"/checkout"+user, nil), http.StatusBadRequest, "FAILED_PRECONDITION")
+
+	// Неудачное создание заказа оставляет корзину как была
+	expectStatus(t, env.do(http.MethodPost, cart+"/items"+user, map[string]interface{}{"dish_id": 2}), http.StatusOK)
+	env.backend.Orders.Fail("CreateOrder", fakes.Fault{Code: codes.Unavailable, Times: 1})
+	expectError(t, env.do(http.MethodPost, cart+"/checkout"+user, nil), http.StatusServiceUnavailable, "UNAVAILABLE")
+	body = expectStatus(t, env.do(http.MethodGet, cart+user, nil), http.StatusOK)
+	if body["total_quantity"] != float64(1) {
+		t.Errorf("cart after failed checkout = %v, want 1 item", body)
+	}
+
+	body = expectStatus(t, env.do(http.MethodDelete, cart+user, nil), http.StatusOK)
+	if body["total_quantity"] != float64(0) {
+		t.Errorf("cart after clear = %v, want empty", body)
+	}
+}
+
+func TestOrderEventsSSE(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"})
+	srv := env.serve()
+
+	resp := openSSE(t, srv.URL+"/api/v1/orders/1/events")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("status = %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	r := bufio.NewReader(resp.Body)
+
+	ev := readSSE(t, r)
+	if ev.event != "order" || ev.id != "1" {
+		t.Fatalf("first event = %+v, want order with id 1", ev)
+	}
+
+	env.backend.Orders.SetStatus(1, "confirmed")
+	ev = readSSE(t, r)
+	if order := ev.data["order"].(map[string]interface{}); ev.id != "2" || order["status"] != "confirmed" {
+		t.Fatalf("second event = %+v, want confirmed with id 2", ev)
+	}
+
+	// Повторное подключение получает только пропущенные события
+	replay := openSSE(t, srv.URL+"/api/v1/orders/1/events", "Last-Event-ID", "2")
+	replayReader := bufio.NewReader(replay.Body)
+
+	env.backend.Orders.SetStatus(1, "cancelled")
+	for _, r := range []*bufio.Reader{r, replayReader} {
+		if ev := readSSE(t, r); ev.id != "3" {
+			t.Fatalf("event = %+v, want id 3", ev)
+		}
+		if ev := readSSE(t, r); ev.event != "end" {
+			t.Fatalf("event = %+v, want end", ev)
+		}
+	}
+	if n := len(env.backend.Orders.Calls("WatchOrder")); n != 1 {
+		t.Errorf("WatchOrder calls = %d, want 1 shared stream", n)
+	}
+
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/999/events", nil), http.StatusNotFound, "NOT_FOUND")
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/1/events", nil, "Last-Event-ID", "x"),
+		http.StatusBadRequest, "INVALID_ARGUMENT")
+}
+
+func TestOrderEventsSSEResume(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"})
+	srv := env.serve()
+
+	resp := openSSE(t, srv.URL+"/api/v1/orders/1/events")
+	r := bufio.NewReader(resp.Body)
+	readSSE(t, r)
+	env.backend.Orders.SetStatus(1, "confirmed")
+	if ev := readSSE(t, r); ev.id != "2" {
+		t.Fatalf("event = %+v, want id 2", ev)
+	}
+
+	// Единственный клиент отключается, и поток к OrderService закрывается
+	resp.Body.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for env.backend.Orders.Watchers(1) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("watch stream was not closed after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Новый поток начинается с текущего состояния, которое у клиента уже есть
+	resumed := openSSE(t, srv.URL+"/api/v1/orders/1/events", "Last-Event-ID", "2")
+	r = bufio.NewReader(resumed.Body)
+	env.backend.Orders.SetStatus(1, "cancelled")
+	if ev := readSSE(t, r); ev.id != "3" {
+		t.Fatalf("first event after resume = %+v, want id 3", ev)
+	}
+}
+
+func TestOrderEventsWebSocket(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"})
+	srv := env.serve()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/orders/1/events/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	read := func() map[string]interface{} {
+		t.Helper()
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read message: %v", err)
+		}
+		return msg
+	}
+
+	if msg := read(); msg["type"] != "order" || msg["id"] != "1" {
+		t.Fatalf("first message = %v", msg)
+	}
+
+	env.backend.Orders.SetStatus(1, "cancelled")
+	msg := read()
+	if order := msg["order"].(map[string]interface{}); msg["type"] != "order" || order["status"] != "cancelled" {
+		t.Fatalf("second message = %v", msg)
+	}
+	if msg := read(); msg["type"] != "end" {
+		t.Fatalf("third message = %v, want end", msg)
+	}
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("read after end = %v, want normal closure", err)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	env := newTestEnv(t, envConfig{auth: true})
+	env.backend.Orders.Seed(
+		&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}},
+		&pbOrders.OrderResponse{Id: 2, UserId: 6, Items: []int64{1}},
+	)
+	user := bearer(t, 5)
+	admin := bearer(t, 100, testAdminRole)
+
+	rec := env.do(http.MethodGet, "/api/v1/orders/1", nil)
+	expectError(t, rec, http.StatusUnauthorized, "UNAUTHENTICATED")
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("401 response has no WWW-Authenticate header")
+	}
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/1", nil, "Authorization", "Bearer garbage"),
+		http.StatusUnauthorized, "UNAUTHENTICATED")
+
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/1", nil, "Authorization", user), http.StatusOK)
+	expectError(t, env.do(http.MethodGet, "/api/v1/orders/2", nil, "Authorization", user), http.StatusForbidden, "PERMISSION_DENIED")
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/2", nil, "Authorization", admin), http.StatusOK)
+	expectError(t, env.do(http.MethodGet, "/api/v1/users/6/orders", nil, "Authorization", user), http.StatusForbidden, "PERMISSION_DENIED")
+
+	body := expectStatus(t, env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{"items": []int64{2}}, "Authorization", user),
+		http.StatusCreated)
+	if body["user_id"] != float64(5) {
+		t.Errorf("user_id = %v, want 5 from token", body["user_id"])
+	}
+	expectError(t, env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{"user_id": 6, "items": []int64{2}}, "Authorization", user),
+		http.StatusForbidden, "PERMISSION_DENIED")
+
+	body = expectStatus(t, env.do(http.MethodGet, "/api/v1/cart", nil, "Authorization", user), http.StatusOK)
+	if body["user_id"] != float64(5) {
+		t.Errorf("cart user_id = %v, want 5 from token", body["user_id"])
+	}
+
+	// Проверки здоровья доступны без токена
+	expectStatus(t, env.do(http.MethodGet, "/livez", nil), http.StatusOK)
+}
+
+func TestRateLimit(t *testing.T) {
+	env := newTestEnv(t, envConfig{rateLimit: &api.RateLimitOptions{
+		Menu:    ratelimit.Limit{Rate: 100, Burst: 100},
+		Orders:  ratelimit.Limit{Rate: 0.1, Burst: 2},
+		APIKeys: map[string]string{"partner": "partner-secret"},
+	}})
+	env.backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}})
+
+	for i := 0; i < 2; i++ {
+		rec := env.do(http.MethodGet, "/api/v1/orders/1", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("RateLimit-Limit = %q, want 2", rec.Header().Get("RateLimit-Limit"))
+		}
+	}
+
+	rec := env.do(http.MethodGet, "/api/v1/cart?user_id=5", nil)
+	expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("429 headers = %v", rec.Header())
+	}
+
+	// Неизвестный X-API-Key и X-Forwarded-For от недоверенного клиента не
+	// дают нового лимита
+	for _, key := range []string{"partner", "partner-2"} {
+		rec := env.do(http.MethodGet, "/api/v1/orders/1", nil, "X-API-Key", key)
+		expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+	}
+	rec = env.do(http.MethodGet, "/api/v1/orders/1", nil, "X-Forwarded-For", "203.0.113.7")
+	expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+
+	// Тело запроса сверх лимита не проверяется
+	rec = env.do(http.MethodPost, "/api/v1/orders/", map[string]interface{}{"user_id": "seven"})
+	expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+	rec = env.do(http.MethodPost, "/graphql", map[string]interface{}{"query": ""})
+	expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+
+	// Другие группы и клиенты с известным ключом считаются отдельно
+	expectStatus(t, env.do(http.MethodGet, "/api/v1/menu/dishes", nil), http.StatusOK)
+	for i := 0; i < 2; i++ {
+		expectStatus(t, env.do(http.MethodGet, "/api/v1/orders/1", nil, "X-API-Key", "partner-secret"), http.StatusOK)
+	}
+	rec = env.do(http.MethodGet, "/api/v1/orders/1", nil, "X-API-Key", "partner-secret")
+	expectError(t, rec, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED")
+}
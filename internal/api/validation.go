@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/anyviewww/bff-service/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// Максимальный размер тела запроса. Самое большое тело - заказ, и ему
+// с запасом хватает
+const maxRequestBodyBytes = 1 << 20
+
+var requestValidator = sync.OnceValue(func() *openapi.Validator {
+	return openapi.NewValidator(OpenAPI())
+})
+
+// ValidateRequest проверяет параметры пути, запроса, заголовки и JSON-тело по
+// спецификации OpenAPI. Все найденные ошибки возвращаются одним ответом 400
+// со списком error.fields, поэтому обработчики получают уже корректные по
+// форме данные. Маршруты без описания в спецификации не проверяются.
+func (h *Handler) ValidateRequest(c *gin.Context) {
+	path, ok := OpenAPI().Paths[openapi.PathFromGin(c.FullPath())]
+	if !ok {
+		c.Next()
+		return
+	}
+	op := path[strings.ToLower(c.Request.Method)]
+	if op == nil {
+		c.Next()
+		return
+	}
+
+	validator := requestValidator()
+	var fields []openapi.FieldError
+	for _, p := range op.Parameters {
+		raw, present := parameterValue(c, p)
+		fields = append(fields, validator.ValidateParameter(p, raw, present)...)
+	}
+
+	if op.RequestBody != nil {
+		bodyFields, err := validateBody(c, validator, op.RequestBody)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			respondHTTPError(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "Request body is too large")
+			return
+		case err != nil:
+			respondError(c, codes.InvalidArgument, "Cannot read request body")
+			return
+		}
+		fields = append(fields, bodyFields...)
+	}
+
+	if len(fields) > 0 {
+		respondValidationError(c, fields)
+		return
+	}
+	c.Next()
+}
+
+func parameterValue(c *gin.Context, p *openapi.Parameter) (string, bool) {
+	switch p.In {
+	case openapi.InPath:
+		return c.Params.Get(p.Name)
+	case openapi.InQuery:
+		return c.GetQuery(p.Name)
+	case openapi.InHeader:
+		values := c.Request.Header.Values(p.Name)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	}
+	return "", false
+}
+
+// validateBody читает тело, проверяет его и возвращает в запрос для
+// обработчика.
+func validateBody(c *gin.Context, validator *openapi.Validator, body *openapi.RequestBody) ([]openapi.FieldError, error) {
+	var raw []byte
+	if c.Request.Body != nil {
+		var err error
+		raw, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestBodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if body.Required {
+			return []openapi.FieldError{{Path: "body", Reason: "is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []openapi.FieldError{{Path: "body", Reason: "must be valid JSON"}}, nil
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return []openapi.FieldError{{Path: "body", Reason: "must contain a single JSON value"}}, nil
+	}
+
+	media, ok := body.Content["application/json"]
+	if !ok || media.Schema == nil {
+		return nil, nil
+	}
+	return validator.ValidateValue(media.Schema, value, "body"), nil
+}
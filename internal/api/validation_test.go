@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldErrors возвращает error.fields ответа в виде "path: reason".
+func fieldErrors(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+
+	apiErr := expectError(t, rec, http.StatusBadRequest, "INVALID_ARGUMENT")
+	list, ok := apiErr["fields"].([]interface{})
+	if !ok {
+		t.Fatalf("error has no fields: %s", rec.Body.String())
+	}
+	var result []string
+	for _, item := range list {
+		f := item.(map[string]interface{})
+		result = append(result, f["path"].(string)+": "+f["reason"].(string))
+	}
+	return result
+}
+
+func TestValidationReportsAllFields(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	rec := env.do(http.MethodPut, "/api/v1/orders/abc", map[string]interface{}{
+		"status":  "flying",
+		"user_id": -1,
+		"order_items": []map[string]interface{}{
+			{"dish_id": 1, "quantity": 100, "modifiers": []string{"", "острый"}},
+			{"quantity": 1.5, "notes": strings.Repeat("я", 501)},
+		},
+	})
+	got := fieldErrors(t, rec)
+	want := []string{
+		"path.id: must be an integer",
+		"body.order_items[0].modifiers[0]: must not be empty",
+		"body.order_items[0].quantity: must be less than or equal to 99",
+		"body.order_items[1].dish_id: is required",
+		"body.order_items[1].notes: must be at most 500 characters",
+		"body.order_items[1].quantity: must be an integer",
+		"body.status: must be one of: cancelled, confirmed, cooking, created, delivered, ready",
+		"body.user_id: must be greater than or equal to 0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if n := len(env.backend.Orders.Calls("GetOrder")); n != 0 {
+		t.Errorf("invalid request reached the backend: %d GetOrder calls", n)
+	}
+}
+
+func TestValidationParameters(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []string
+		want    []string
+	}{
+		{
+			name: "query",
+			path: "/api/v1/menu/dishes?limit=0&min_calories=abc&sort=price&type_id=1,x",
+			want: []string{
+				"query.type_id: must match pattern ^-?\\d+(\\s*,\\s*-?\\d+)*$",
+				"query.min_calories: must be a number",
+				"query.sort: must match pattern ^-?(id|name|calories|proteins|fats|carbohydrates)$",
+				"query.limit: must be greater than or equal to 1",
+			},
+		},
+		{
+			name: "int32 overflow",
+			path: "/api/v1/menu/dishes/4294967296",
+			want: []string{"path.id: is out of range"},
+		},
+		{
+			name: "not a plain number",
+			path: "/api/v1/users/0x10/orders?page_size=1e1",
+			want: []string{"path.user_id: must be an integer", "query.page_size: must be an integer"},
+		},
+		{
+			name:    "header",
+			path:    "/api/v1/orders/1/events",
+			headers: []string{"Last-Event-ID", "x"},
+			want:    []string{"header.Last-Event-ID: must match pattern ^[0-9]+$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldErrors(t, env.do(http.MethodGet, tt.path, nil, tt.headers...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("fields:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidationBody(t *testing.T) {
+	env := newTestEnv(t, envConfig{})
+
+	raw := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		env.engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"", []string{"body: is required"}},
+		{"{", []string{"body: must be valid JSON"}},
+		{`{"user_id": 1} {}`, []string{"body: must contain a single JSON value"}},
+		{`[]`, []string{"body: must be an object"}},
+		{`{"user_id": "7", "items": null}`, []string{"body.items: must not be null", "body.user_id: must be an integer"}},
+		{`{"user_id": 1e1000000000}`, []string{"body.user_id: is out of range"}},
+		{`{"user_id": 99999999999999999999999}`, []string{"body.user_id: is out of range"}},
+	}
+	for _, tt := range tests {
+		got := fieldErrors(t, raw(tt.body))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("body %q: fields %q, want %q", tt.body, got, tt.want)
+		}
+	}
+
+	rec := raw(`{"user_id": 1, "items": [1], "padding": "` + strings.Repeat("x", 1<<20) + `"}`)
+	expectError(t, rec, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE")
+
+	// Тело после проверки доступно обработчику
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/", bytes.NewReader([]byte(`{"user_id": 3, "items": [1, 1]}`)))
+	rec = httptest.NewRecorder()
+	env.engine.ServeHTTP(rec, req)
+	body := expectStatus(t, rec, http.StatusCreated)
+	if body["user_id"] != float64(3) {
+		t.Fatalf("created order = %v", body)
+	}
+}
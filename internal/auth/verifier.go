@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Допустимое расхождение часов при проверке exp/nbf
+const clockLeeway = 30 * time.Second
+
+// Principal - аутентифицированный пользователь запроса.
+type Principal struct {
+	Subject string
+	UserID  uint64
+	Admin   bool
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Role  string   `json:"role,omitempty"`
+}
+
+type Options struct {
+	// HMACSecret включает проверку токенов HS256
+	HMACSecret string
+	// PublicKeyFile - PEM с открытым ключом RSA для RS256
+	PublicKeyFile string
+	// JWKSFile - файл JWKS с ключами RS256, выбираются по kid
+	JWKSFile string
+	Issuer   string
+	Audience string
+	// AdminRole - роль, дающая доступ к чужим заказам
+	AdminRole string
+}
+
+type Verifier struct {
+	hmacSecret []byte
+	// rsaKeys по kid; ключ из PEM-файла хранится под пустым kid
+	rsaKeys   map[string]*rsa.PublicKey
+	parser    *jwt.Parser
+	adminRole string
+}
+
+func NewVerifier(opts Options) (*Verifier, error) {
+	v := &Verifier{
+		rsaKeys:   make(map[string]*rsa.PublicKey),
+		adminRole: opts.AdminRole,
+	}
+
+	if opts.HMACSecret != "" {
+		v.hmacSecret = []byte(opts.HMACSecret)
+	}
+
+	if opts.PublicKeyFile != "" {
+		data, err := os.ReadFile(opts.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		v.rsaKeys[""] = key
+	}
+
+	if opts.JWKSFile != "" {
+		keys, err := loadJWKS(opts.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			v.rsaKeys[kid] = key
+		}
+	}
+
+	var methods []string
+	if v.hmacSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no JWT verification keys configured")
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockLeeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	v.parser = jwt.NewParser(parserOpts...)
+
+	return v, nil
+}
+
+// Verify проверяет подпись и стандартные поля токена. Subject токена должен
+// быть числовым id пользователя в OrderService.
+func (v *Verifier) Verify(tokenString string) (*Principal, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, errors.New("token subject is not a valid user id")
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		UserID:  userID,
+		Admin:   v.hasAdminRole(&claims),
+	}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// Токен без kid принимается, если ключ RSA единственный
+		if kid == "" && len(v.rsaKeys) == 1 {
+			for _, key := range v.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+func (v *Verifier) hasAdminRole(claims *Claims) bool {
+	if v.adminRole == "" {
+		return false
+	}
+	if claims.Role == v.adminRole {
+		return true
+	}
+	for _, role := range claims.Roles {
+		if role == v.adminRole {
+			return true
+		}
+	}
+	return false
+}
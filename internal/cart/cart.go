@@ -0,0 +1,113 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxItems - максимальное число разных блюд в корзине
+	MaxItems = 50
+	// MaxQuantity - максимальное количество одного блюда
+	MaxQuantity = 99
+)
+
+var (
+	// ErrCheckoutInProgress - корзина оформляется в заказ и не может меняться
+	ErrCheckoutInProgress = errors.New("cart checkout is in progress")
+	ErrTooManyItems       = fmt.Errorf("cart cannot contain more than %d different dishes", MaxItems)
+	ErrInvalidQuantity    = fmt.Errorf("quantity must be between 1 and %d", MaxQuantity)
+	ErrItemNotFound       = errors.New("dish is not in the cart")
+)
+
+type Item struct {
+	DishID   int64 `json:"dish_id"`
+	Quantity int   `json:"quantity"`
+}
+
+// Cart - корзина пользователя. Позиции хранятся в порядке добавления.
+type Cart struct {
+	Items     []Item    `json:"items"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Add увеличивает количество блюда или добавляет новую позицию.
+func (c *Cart) Add(dishID int64, quantity int) error {
+	if quantity < 1 || quantity > MaxQuantity {
+		return ErrInvalidQuantity
+	}
+	if i := c.index(dishID); i >= 0 {
+		if c.Items[i].Quantity+quantity > MaxQuantity {
+			return ErrInvalidQuantity
+		}
+		c.Items[i].Quantity += quantity
+		return nil
+	}
+	return c.append(dishID, quantity)
+}
+
+// SetQuantity задаёт количество блюда, 0 удаляет позицию.
+func (c *Cart) SetQuantity(dishID int64, quantity int) error {
+	if quantity < 0 || quantity > MaxQuantity {
+		return ErrInvalidQuantity
+	}
+	if quantity == 0 {
+		c.Remove(dishID)
+		return nil
+	}
+	if i := c.index(dishID); i >= 0 {
+		c.Items[i].Quantity = quantity
+		return nil
+	}
+	return c.append(dishID, quantity)
+}
+
+// Remove удаляет позицию и сообщает, была ли она в корзине.
+func (c *Cart) Remove(dishID int64) bool {
+	i := c.index(dishID)
+	if i < 0 {
+		return false
+	}
+	c.Items = append(c.Items[:i], c.Items[i+1:]...)
+	return true
+}
+
+func (c *Cart) Clear() {
+	c.Items = nil
+}
+
+func (c *Cart) Empty() bool {
+	return len(c.Items) == 0
+}
+
+// TotalQuantity возвращает общее число порций в корзине.
+func (c *Cart) TotalQuantity() int {
+	total := 0
+	for _, item := range c.Items {
+		total += item.Quantity
+	}
+	return total
+}
+
+func (c *Cart) index(dishID int64) int {
+	for i, item := range c.Items {
+		if item.DishID == dishID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Cart) append(dishID int64, quantity int) error {
+	if len(c.Items) >= MaxItems {
+		return ErrTooManyItems
+	}
+	c.Items = append(c.Items, Item{DishID: dishID, Quantity: quantity})
+	return nil
+}
+
+func (c Cart) clone() Cart {
+	c.Items = append([]Item(nil), c.Items...)
+	return c
+}
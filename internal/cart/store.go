@@ -0,0 +1,157 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Время, на которое корзина блокируется при оформлении заказа. Если
+// оформление не завершилось (например, процесс упал), блокировка снимается
+// сама по истечении этого времени.
+const checkoutLease = time.Minute
+
+// Store хранит корзины пользователей. Реализация в памяти подходит для
+// одного экземпляра сервиса, для нескольких нужен общий бэкенд, например
+// Redis, с атомарными Update и BeginCheckout.
+type Store interface {
+	// Get возвращает корзину владельца, пустую, если её ещё нет.
+	Get(ctx context.Context, owner string) (Cart, error)
+	// Update атомарно применяет fn к корзине и сохраняет результат, если fn
+	// не вернула ошибку. Во время оформления возвращает ErrCheckoutInProgress.
+	Update(ctx context.Context, owner string, fn func(*Cart) error) (Cart, error)
+	// BeginCheckout блокирует корзину от изменений и повторного оформления
+	// и возвращает её содержимое.
+	BeginCheckout(ctx context.Context, owner string) (Cart, error)
+	// CompleteCheckout очищает корзину и снимает блокировку.
+	CompleteCheckout(ctx context.Context, owner string) error
+	// AbortCheckout снимает блокировку, сохраняя содержимое корзины.
+	AbortCheckout(ctx context.Context, owner string) error
+}
+
+type memoryEntry struct {
+	cart          Cart
+	checkoutUntil time.Time
+	expiresAt     time.Time
+}
+
+func (e *memoryEntry) checkingOut(now time.Time) bool {
+	return now.Before(e.checkoutUntil)
+}
+
+// MemoryStore хранит корзины в памяти процесса. Корзины, которые не
+// менялись дольше ttl, удаляются.
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:       ttl,
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, owner string) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry := s.lookup(owner, time.Now()); entry != nil {
+		return entry.cart.clone(), nil
+	}
+	return Cart{}, nil
+}
+
+func (s *MemoryStore) Update(_ context.Context, owner string, fn func(*Cart) error) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	entry := s.lookup(owner, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+	}
+	if entry.checkingOut(now) {
+		return Cart{}, ErrCheckoutInProgress
+	}
+
+	// fn работает с копией, чтобы ошибка не оставила корзину изменённой
+	updated := entry.cart.clone()
+	if err := fn(&updated); err != nil {
+		return Cart{}, err
+	}
+	updated.UpdatedAt = now
+
+	entry.cart = updated
+	entry.expiresAt = now.Add(s.ttl)
+	s.entries[owner] = entry
+	return updated.clone(), nil
+}
+
+func (s *MemoryStore) BeginCheckout(_ context.Context, owner string) (Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry := s.lookup(owner, now)
+	if entry == nil {
+		return Cart{}, nil
+	}
+	if entry.checkingOut(now) {
+		return Cart{}, ErrCheckoutInProgress
+	}
+
+	entry.checkoutUntil = now.Add(checkoutLease)
+	return entry.cart.clone(), nil
+}
+
+func (s *MemoryStore) CompleteCheckout(_ context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, owner)
+	return nil
+}
+
+func (s *MemoryStore) AbortCheckout(_ context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[owner]; ok {
+		entry.checkoutUntil = time.Time{}
+	}
+	return nil
+}
+
+// lookup возвращает непросроченную запись владельца или nil.
+func (s *MemoryStore) lookup(owner string, now time.Time) *memoryEntry {
+	entry, ok := s.entries[owner]
+	if !ok {
+		return nil
+	}
+	if !now.Before(entry.expiresAt) && !entry.checkingOut(now) {
+		delete(s.entries, owner)
+		return nil
+	}
+	return entry
+}
+
+// sweep удаляет просроченные корзины не чаще раза в минуту.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for owner, entry := range s.entries {
+		if !now.Before(entry.expiresAt) && !entry.checkingOut(now) {
+			delete(s.entries, owner)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker размыкается после threshold подряд неудачных вызовов бэкенда
+// и в течение openTimeout сразу отвечает Unavailable. После этого пропускается
+// один пробный вызов: при успехе цепь замыкается, при ошибке снова размыкается.
+type CircuitBreaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(name string, threshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:        name,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow сообщает, можно ли выполнить вызов. Если нельзя, возвращается ошибка
+// со статусом Unavailable.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return status.Errorf(codes.Unavailable, "%s service is temporarily unavailable", b.name)
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return status.Errorf(codes.Unavailable, "%s service is temporarily unavailable", b.name)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record учитывает результат вызова, пропущенного через allow.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch classifyCall(err) {
+	case callNeutral:
+		// Ответа бэкенда не было: состояние не меняется, а пробный вызов
+		// можно повторить
+		b.probing = false
+	case callSucceeded:
+		b.failures = 0
+		b.probing = false
+		b.state = BreakerClosed
+	case callFailed:
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+			b.probing = false
+			b.openedAt = time.Now()
+			b.state = BreakerOpen
+		}
+	}
+}
+
+type callOutcome int
+
+const (
+	callSucceeded callOutcome = iota
+	callFailed
+	callNeutral
+)
+
+// classifyCall отделяет отказы бэкенда от обычных прикладных ошибок:
+// NotFound или InvalidArgument означают, что сервис жив и ответил. Internal
+// считается отказом: это сбой на стороне сервиса, а не ошибка запроса.
+// Canceled - вызов отменил клиент, о здоровье бэкенда он ничего не говорит.
+func classifyCall(err error) callOutcome {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+		return callFailed
+	case codes.Canceled:
+		return callNeutral
+	default:
+		return callSucceeded
+	}
+}
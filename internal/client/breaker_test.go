@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyCall(t *testing.T) {
+	tests := []struct {
+		err  error
+		want callOutcome
+	}{
+		{nil, callSucceeded},
+		{status.Error(codes.NotFound, ""), callSucceeded},
+		{status.Error(codes.InvalidArgument, ""), callSucceeded},
+		{status.Error(codes.PermissionDenied, ""), callSucceeded},
+		{status.Error(codes.Unavailable, ""), callFailed},
+		{status.Error(codes.DeadlineExceeded, ""), callFailed},
+		{status.Error(codes.ResourceExhausted, ""), callFailed},
+		{status.Error(codes.Internal, ""), callFailed},
+		{status.Error(codes.Canceled, ""), callNeutral},
+	}
+	for _, tt := range tests {
+		if got := classifyCall(tt.err); got != tt.want {
+			t.Errorf("classifyCall(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// expire делает вид, что openTimeout уже прошёл.
+func expire(b *CircuitBreaker) {
+	b.mu.Lock()
+	b.openedAt = time.Now().Add(-b.openTimeout)
+	b.mu.Unlock()
+}
+
+func expectState(t *testing.T, b *CircuitBreaker, want BreakerState) {
+	t.Helper()
+	if got := b.State(); got != want {
+		t.Fatalf("state = %s, want %s", got, want)
+	}
+}
+
+func expectAllowed(t *testing.T, b *CircuitBreaker, want bool) {
+	t.Helper()
+	err := b.allow()
+	if want && err != nil {
+		t.Fatalf("allow() = %v, want nil", err)
+	}
+	if !want && status.Code(err) != codes.Unavailable {
+		t.Fatalf("allow() = %v, want Unavailable", err)
+	}
+}
+
+var (
+	errUnavailable = status.Error(codes.Unavailable, "backend is down")
+	errCanceled    = status.Error(codes.Canceled, "client went away")
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	b := NewCircuitBreaker("menu", 3, time.Hour)
+
+	// Успешный ответ сбрасывает счётчик неудач
+	b.record(errUnavailable)
+	b.record(errUnavailable)
+	b.record(status.Error(codes.NotFound, ""))
+	b.record(errUnavailable)
+	b.record(errUnavailable)
+	expectState(t, b, BreakerClosed)
+
+	b.record(errUnavailable)
+	expectState(t, b, BreakerOpen)
+	expectAllowed(t, b, false)
+
+	// После openTimeout пропускается пробный вызов, его неудача снова
+	// размыкает цепь
+	expire(b)
+	expectAllowed(t, b, true)
+	expectState(t, b, BreakerHalfOpen)
+	b.record(errUnavailable)
+	expectState(t, b, BreakerOpen)
+	expectAllowed(t, b, false)
+
+	// Успешный пробный вызов замыкает цепь
+	expire(b)
+	expectAllowed(t, b, true)
+	b.record(nil)
+	expectState(t, b, BreakerClosed)
+	expectAllowed(t, b, true)
+
+	// Счётчик неудач после замыкания начинается заново
+	b.record(errUnavailable)
+	b.record(errUnavailable)
+	expectState(t, b, BreakerClosed)
+}
+
+func TestBreakerHalfOpenAdmitsOneProbe(t *testing.T) {
+	b := NewCircuitBreaker("menu", 1, time.Hour)
+	b.record(errUnavailable)
+	expire(b)
+
+	expectAllowed(t, b, true)
+	// Пока пробный вызов не завершён, остальные отклоняются
+	expectAllowed(t, b, false)
+	expectAllowed(t, b, false)
+	expectState(t, b, BreakerHalfOpen)
+
+	b.record(nil)
+	expectAllowed(t, b, true)
+	expectAllowed(t, b, true)
+}
+
+func TestBreakerCanceledIsNeutral(t *testing.T) {
+	b := NewCircuitBreaker("menu", 2, time.Hour)
+
+	// Отмена не сбрасывает и не увеличивает счётчик неудач
+	b.record(errUnavailable)
+	b.record(errCanceled)
+	b.record(errCanceled)
+	expectState(t, b, BreakerClosed)
+	b.record(errUnavailable)
+	expectState(t, b, BreakerOpen)
+
+	// Отменённый пробный вызов не замыкает и не размыкает цепь, а
+	// следующий пробный вызов пропускается
+	expire(b)
+	expectAllowed(t, b, true)
+	b.record(errCanceled)
+	expectState(t, b, BreakerHalfOpen)
+	expectAllowed(t, b, true)
+	expectAllowed(t, b, false)
+	b.record(nil)
+	expectState(t, b, BreakerClosed)
+}
+
+func TestBreakerInterceptor(t *testing.T) {
+	b := NewCircuitBreaker("order", 2, time.Hour)
+	interceptor := breakerInterceptor(b)
+
+	calls := 0
+	result := errUnavailable
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return result
+	}
+	call := func() error {
+		return interceptor(context.Background(), "/orders.OrderService/GetOrder", nil, nil, nil, invoker)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call(); !errors.Is(err, errUnavailable) {
+			t.Fatalf("call %d: err = %v, want the backend error", i, err)
+		}
+	}
+	// Разомкнутая цепь отвечает сама, не вызывая бэкенд
+	if err := call(); status.Code(err) != codes.Unavailable || calls != 2 {
+		t.Fatalf("open breaker: err = %v, calls = %d, want Unavailable without a call", err, calls)
+	}
+
+	expire(b)
+	result = nil
+	if err := call(); err != nil || calls != 3 {
+		t.Fatalf("probe: err = %v, calls = %d", err, calls)
+	}
+	expectState(t, b, BreakerClosed)
+
+	// Без breaker вызовы проходят как есть
+	if err := breakerInterceptor(nil)(context.Background(), "/orders.OrderService/GetOrder", nil, nil, nil, invoker); err != nil || calls != 4 {
+		t.Errorf("nil breaker: err = %v, calls = %d", err, calls)
+	}
+}
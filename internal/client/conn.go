@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Идемпотентные методы, которые можно безопасно повторять при Unavailable
+var idempotentMethods = map[string]bool{
+	"/dishes.DishService/GetDishes":   true,
+	"/orders.OrderService/GetOrder":   true,
+	"/orders.OrderService/ListOrders": true,
+}
+
+// Верхняя граница задержки между повторами
+const maxRetryBackoff = 5 * time.Second
+
+type CallOptions struct {
+	// Timeout - дедлайн одной попытки вызова по умолчанию
+	Timeout time.Duration
+	// MethodTimeouts переопределяет Timeout для отдельных методов,
+	// ключ - короткое имя метода, например "GetDishes"
+	MethodTimeouts map[string]time.Duration
+	// MaxRetries - число повторов идемпотентных вызовов после первой попытки
+	MaxRetries int
+	// RetryBackoff - базовая задержка между повторами
+	RetryBackoff time.Duration
+	// Interceptors выполняются до circuit breaker и повторов и видят
+	// каждый вызов целиком, например для метрик
+	Interceptors []grpc.UnaryClientInterceptor
+	// StreamInterceptors применяются к потоковым вызовам, для которых
+	// дедлайны, повторы и circuit breaker не используются
+	StreamInterceptors []grpc.StreamClientInterceptor
+}
+
+func (o CallOptions) timeoutFor(method string) time.Duration {
+	if t, ok := o.MethodTimeouts[path.Base(method)]; ok {
+		return t
+	}
+	return o.Timeout
+}
+
+// Dial создаёт соединение без ожидания готовности бэкенда: подключение
+// устанавливается в фоне и восстанавливается самим gRPC, а недоступность
+// бэкенда проявляется как ошибки вызовов. Без creds соединение не шифруется.
+func Dial(addr string, creds credentials.TransportCredentials, opts CallOptions, breaker *CircuitBreaker, extra ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	interceptors := append([]grpc.UnaryClientInterceptor{}, opts.Interceptors...)
+	interceptors = append(interceptors, breakerInterceptor(breaker), retryInterceptor(opts))
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+		grpc.WithChainStreamInterceptor(opts.StreamInterceptors...),
+	}
+	dialOpts = append(dialOpts, extra...)
+
+	return grpc.Dial(addr, dialOpts...)
+}
+
+func breakerInterceptor(breaker *CircuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if breaker == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if err := breaker.allow(); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		breaker.record(err)
+		return err
+	}
+}
+
+// retryInterceptor ограничивает каждую попытку дедлайном метода и повторяет
+// идемпотентные вызовы с экспоненциальной задержкой и случайным разбросом.
+func retryInterceptor(callOpts CallOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		attempts := 1
+		if idempotentMethods[method] {
+			attempts += callOpts.MaxRetries
+		}
+		timeout := callOpts.timeoutFor(method)
+
+		var err error
+		for attempt := 0; attempt < attempts; attempt++ {
+			if attempt > 0 {
+				if waitErr := sleepCtx(ctx, backoff(callOpts.RetryBackoff, attempt)); waitErr != nil {
+					return err
+				}
+			}
+
+			err = invokeWithTimeout(ctx, timeout, method, req, reply, cc, invoker, opts...)
+			if status.Code(err) != codes.Unavailable {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func invokeWithTimeout(ctx context.Context, timeout time.Duration, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// backoff возвращает задержку перед повтором номер attempt (начиная с 1)
+// по схеме "full jitter": случайное значение от 0 до base*2^(attempt-1).
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	ceiling := base << (attempt - 1)
+	if ceiling <= 0 || ceiling > maxRetryBackoff {
+		ceiling = maxRetryBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
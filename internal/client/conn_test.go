@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedInvoker возвращает ошибки из results по очереди, затем nil.
+type scriptedInvoker struct {
+	results   []error
+	calls     int
+	deadlines []time.Duration
+}
+
+func (s *scriptedInvoker) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadlines = append(s.deadlines, time.Until(deadline))
+	} else {
+		s.deadlines = append(s.deadlines, 0)
+	}
+	s.calls++
+	if s.calls <= len(s.results) {
+		return s.results[s.calls-1]
+	}
+	return nil
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "")
+	notFound := status.Error(codes.NotFound, "")
+
+	tests := []struct {
+		name       string
+		method     string
+		maxRetries int
+		results    []error
+		wantCalls  int
+		wantCode   codes.Code
+	}{
+		{
+			name:       "idempotent retried up to the limit",
+			method:     "/dishes.DishService/GetDishes",
+			maxRetries: 2,
+			results:    []error{unavailable, unavailable, unavailable, unavailable},
+			wantCalls:  3,
+			wantCode:   codes.Unavailable,
+		},
+		{
+			name:       "idempotent recovers",
+			method:     "/orders.OrderService/GetOrder",
+			maxRetries: 2,
+			results:    []error{unavailable},
+			wantCalls:  2,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "retries disabled",
+			method:     "/orders.OrderService/ListOrders",
+			maxRetries: 0,
+			results:    []error{unavailable},
+			wantCalls:  1,
+			wantCode:   codes.Unavailable,
+		},
+		{
+			name:       "other errors are not retried",
+			method:     "/orders.OrderService/GetOrder",
+			maxRetries: 2,
+			results:    []error{notFound},
+			wantCalls:  1,
+			wantCode:   codes.NotFound,
+		},
+		{
+			name:       "deadline is not retried",
+			method:     "/orders.OrderService/GetOrder",
+			maxRetries: 2,
+			results:    []error{status.Error(codes.DeadlineExceeded, "")},
+			wantCalls:  1,
+			wantCode:   codes.DeadlineExceeded,
+		},
+		{
+			name:       "non-idempotent method is not retried",
+			method:     "/orders.OrderService/CreateOrder",
+			maxRetries: 2,
+			results:    []error{unavailable},
+			wantCalls:  1,
+			wantCode:   codes.Unavailable,
+		},
+		{
+			name:       "update is not retried",
+			method:     "/orders.OrderService/UpdateOrder",
+			maxRetries: 2,
+			results:    []error{unavailable},
+			wantCalls:  1,
+			wantCode:   codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoker := &scriptedInvoker{results: tt.results}
+			interceptor := retryInterceptor(CallOptions{MaxRetries: tt.maxRetries})
+
+			err := interceptor(context.Background(), tt.method, nil, nil, nil, invoker.invoke)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("err = %v, want %s", err, tt.wantCode)
+			}
+			if invoker.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", invoker.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryInterceptorTimeouts(t *testing.T) {
+	interceptor := retryInterceptor(CallOptions{
+		Timeout:        time.Second,
+		MethodTimeouts: map[string]time.Duration{"GetDishes": time.Minute},
+		MaxRetries:     1,
+	})
+
+	// Дедлайн задаётся каждой попытке отдельно
+	invoker := &scriptedInvoker{results: []error{status.Error(codes.Unavailable, "")}}
+	if err := interceptor(context.Background(), "/dishes.DishService/GetDishes", nil, nil, nil, invoker.invoke); err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range invoker.deadlines {
+		if d <= 30*time.Second || d > time.Minute {
+			t.Errorf("attempt %d deadline = %v, want the GetDishes timeout", i+1, d)
+		}
+	}
+
+	invoker = &scriptedInvoker{}
+	if err := interceptor(context.Background(), "/orders.OrderService/GetOrder", nil, nil, nil, invoker.invoke); err != nil {
+		t.Fatal(err)
+	}
+	if d := invoker.deadlines[0]; d <= 0 || d > time.Second {
+		t.Errorf("GetOrder deadline = %v, want the default timeout", d)
+	}
+}
+
+func TestRetryInterceptorCanceledDuringBackoff(t *testing.T) {
+	interceptor := retryInterceptor(CallOptions{MaxRetries: 3, RetryBackoff: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	invoker := &scriptedInvoker{results: []error{status.Error(codes.Unavailable, "")}}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// Отмена во время ожидания возвращает ошибку последней попытки
+	err := interceptor(ctx, "/orders.OrderService/GetOrder", nil, nil, nil, invoker.invoke)
+	if status.Code(err) != codes.Unavailable || invoker.calls != 1 {
+		t.Errorf("err = %v, calls = %d, want Unavailable after 1 call", err, invoker.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		ceiling time.Duration
+	}{
+		{0, 1, 0},
+		{100 * time.Millisecond, 1, 100 * time.Millisecond},
+		{100 * time.Millisecond, 3, 400 * time.Millisecond},
+		{100 * time.Millisecond, 10, maxRetryBackoff},
+		// Сдвиг переполняется, потолок всё равно maxRetryBackoff
+		{time.Second, 100, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := backoff(tt.base, tt.attempt); d < 0 || d > tt.ceiling {
+				t.Fatalf("backoff(%v, %d) = %v, want within [0, %v]", tt.base, tt.attempt, d, tt.ceiling)
+			}
+		}
+	}
+}
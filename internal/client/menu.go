@@ -2,26 +2,26 @@ package client
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	pb "github.com/anyviewww/bff-service/proto/dishes"
 	"google.golang.org/grpc"
 )
 
 type MenuClient struct {
-	client pb.DishServiceClient
-	conn   *grpc.ClientConn
+	pb.DishServiceClient
+	conn *grpc.ClientConn
 }
 
 func NewMenuClient(conn *grpc.ClientConn) *MenuClient {
 	return &MenuClient{
-		client: pb.NewDishServiceClient(conn),
-		conn:   conn,
+		DishServiceClient: pb.NewDishServiceClient(conn),
+		conn:              conn,
 	}
 }
 
 func (c *MenuClient) GetDish(ctx context.Context, id int32) (*pb.Dish, error) {
-	resp, err := c.client.GetDishes(ctx, &pb.DishRequest{Id: id})
+	resp, err := c.GetDishes(ctx, &pb.DishRequest{Id: id})
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +33,6 @@ func (c *MenuClient) GetDish(ctx context.Context, id int32) (*pb.Dish, error) {
 
 func (c *MenuClient) Close() {
 	if err := c.conn.Close(); err != nil {
-		log.Printf("Failed to close menu connection: %v", err)
+		slog.Error("failed to close menu connection", slog.Any("error", err))
 	}
 }
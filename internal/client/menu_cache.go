@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/anyviewww/bff-service/proto/dishes"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+)
+
+// Ключ кэша для полного списка блюд: DishRequest с нулевым id
+const allDishesKey int32 = 0
+
+type menuCacheEntry struct {
+	resp      *pb.DishesResponse
+	fetchedAt time.Time
+}
+
+// MenuCache - кэш ответов DishService в памяти процесса. Свежие записи
+// (моложе ttl) отдаются без обращения к сервису, одновременные промахи по
+// одному ключу объединяются в один запрос. Устаревшая запись, пока она
+// моложе ttl+staleTTL, отдаётся сразу, а обновляется в фоне
+// (stale-while-revalidate); если обновить её не удалось, следующий запрос
+// снова получит её и запустит новое обновление.
+//
+// Пустые ответы (блюдо не найдено) не кэшируются, записи старше
+// ttl+staleTTL удаляются, поэтому размер кэша ограничен числом блюд.
+//
+// Ответы разделяются между запросами и не должны изменяться вызывающим кодом.
+type MenuCache struct {
+	next     pb.DishServiceClient
+	ttl      time.Duration
+	staleTTL time.Duration
+
+	mu        sync.RWMutex
+	entries   map[int32]menuCacheEntry
+	lastSweep time.Time
+	group     singleflight.Group
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	stale  atomic.Uint64
+}
+
+type MenuCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Stale   uint64
+	Entries int
+}
+
+func NewMenuCache(next pb.DishServiceClient, ttl, staleTTL time.Duration) *MenuCache {
+	return &MenuCache{
+		next:      next,
+		ttl:       ttl,
+		staleTTL:  staleTTL,
+		entries:   make(map[int32]menuCacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *MenuCache) GetDishes(ctx context.Context, in *pb.DishRequest, opts ...grpc.CallOption) (*pb.DishesResponse, error) {
+	key := in.GetId()
+
+	entry, ok := c.lookup(key)
+	if ok {
+		age := time.Since(entry.fetchedAt)
+		if age < c.ttl {
+			c.hits.Add(1)
+			return entry.resp, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			c.stale.Add(1)
+			c.refresh(ctx, key, opts...)
+			return entry.resp, nil
+		}
+	}
+	c.misses.Add(1)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-c.refresh(ctx, key, opts...):
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*pb.DishesResponse), nil
+	}
+}
+
+// lookup ищет запись по ключу. Отдельное блюдо может быть взято из свежего
+// полного списка, если сам ключ ещё не закэширован.
+func (c *MenuCache) lookup(key int32) (menuCacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entry, ok := c.entries[key]; ok {
+		return entry, true
+	}
+	if key == allDishesKey {
+		return menuCacheEntry{}, false
+	}
+
+	all, ok := c.entries[allDishesKey]
+	if !ok || time.Since(all.fetchedAt) >= c.ttl {
+		return menuCacheEntry{}, false
+	}
+	for _, dish := range all.resp.Dishes {
+		if dish.GetId() == key {
+			return menuCacheEntry{
+				resp:      &pb.DishesResponse{Dishes: []*pb.Dish{dish}},
+				fetchedAt: all.fetchedAt,
+			}, true
+		}
+	}
+	return menuCacheEntry{}, false
+}
+
+// refresh запрашивает ключ у сервиса и сохраняет ответ. Одновременные
+// обновления одного ключа объединяются; результат можно не ждать.
+func (c *MenuCache) refresh(ctx context.Context, key int32, opts ...grpc.CallOption) <-chan singleflight.Result {
+	// Запрос выполняется от имени всех ожидающих, а фоновое обновление -
+	// после ответа клиенту, поэтому отмена контекста запроса не должна его
+	// прерывать
+	fetchCtx := context.WithoutCancel(ctx)
+
+	return c.group.DoChan(strconv.Itoa(int(key)), func() (interface{}, error) {
+		resp, err := c.next.GetDishes(fetchCtx, &pb.DishRequest{Id: key}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.GetDishes()) > 0 {
+			c.store(key, resp)
+		}
+		return resp, nil
+	})
+}
+
+func (c *MenuCache) store(key int32, resp *pb.DishesResponse) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = menuCacheEntry{resp: resp, fetchedAt: now}
+
+	// Просроченные записи удаляются не чаще раза в ttl
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for k, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= c.ttl+c.staleTTL {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Purge удаляет все записи кэша.
+func (c *MenuCache) Purge() {
+	c.mu.Lock()
+	c.entries = make(map[int32]menuCacheEntry)
+	c.mu.Unlock()
+}
+
+func (c *MenuCache) Stats() MenuCacheStats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+
+	return MenuCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Stale:   c.stale.Load(),
+		Entries: entries,
+	}
+}
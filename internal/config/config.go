@@ -1,24 +1,334 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"gopkg.in/yaml.v3"
+)
+
+// Config собирается из значений по умолчанию, YAML-файла, переменных
+// окружения и флагов командной строки, именно в таком порядке приоритета.
 type Config struct {
-	MenuServiceAddr  string
-	OrderServiceAddr string
-	ServerPort       string
+	Server      ServerConfig      `yaml:"server"`
+	Menu        BackendConfig     `yaml:"menu"`
+	Order       BackendConfig     `yaml:"order"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	Breaker     BreakerConfig     `yaml:"breaker"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Cart        CartConfig        `yaml:"cart"`
+	Pricing     PricingConfig     `yaml:"pricing"`
+	OrderEvents OrderEventsConfig `yaml:"order_events"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Admin       AdminConfig       `yaml:"admin"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Log         LogConfig         `yaml:"log"`
+	Readiness   ReadinessConfig   `yaml:"readiness"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	GraphQL     GraphQLConfig     `yaml:"graphql"`
+	CORS        CORSConfig        `yaml:"cors"`
+	CertReload  CertReloadConfig  `yaml:"cert_reload"`
+
+	// PrintConfig задаётся флагом --print-config
+	PrintConfig bool `yaml:"-"`
+}
+
+type ServerConfig struct {
+	Port               string        `yaml:"port"`
+	ReadTimeout        time.Duration `yaml:"read_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay"`
+	TLS                ServerTLS     `yaml:"tls"`
+	// TrustedProxies - адреса и подсети прокси, которым доверяется
+	// X-Forwarded-For. По умолчанию пусто: IP клиента - адрес соединения
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// ServerTLS включает HTTPS, если заданы сертификат и ключ. ClientCAFile
+// дополнительно требует клиентские сертификаты (mTLS).
+type ServerTLS struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+type BackendConfig struct {
+	Addr string     `yaml:"addr"`
+	TLS  BackendTLS `yaml:"tls"`
+}
+
+// BackendTLS - TLS для исходящих gRPC-соединений. CertFile и KeyFile
+// включают клиентскую аутентификацию (mTLS).
+type BackendTLS struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+}
+
+type GRPCConfig struct {
+	Timeout        time.Duration            `yaml:"timeout"`
+	MethodTimeouts map[string]time.Duration `yaml:"method_timeouts"`
+	MaxRetries     int                      `yaml:"max_retries"`
+	RetryBackoff   time.Duration            `yaml:"retry_backoff"`
+}
+
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenTimeout      time.Duration `yaml:"open_timeout"`
+}
+
+// CacheConfig - кэш меню, MenuTTL = 0 отключает кэш
+type CacheConfig struct {
+	MenuTTL      time.Duration `yaml:"menu_ttl"`
+	MenuStaleTTL time.Duration `yaml:"menu_stale_ttl"`
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// CartConfig - корзины, не менявшиеся дольше TTL, удаляются
+type CartConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// PricingConfig - расчёт стоимости заказов. Ставки налога задаются в
+// процентах строкой, например "20" или "8.875"
+type PricingConfig struct {
+	Currency         string           `yaml:"currency"`
+	TaxRate          string           `yaml:"tax_rate"`
+	CategoryTaxRates map[int32]string `yaml:"category_tax_rates"`
+}
+
+// OrderEventsConfig - потоки изменений заказов через SSE и WebSocket
+type OrderEventsConfig struct {
+	Heartbeat time.Duration `yaml:"heartbeat"`
+}
+
+// AuthConfig - аутентификация по JWT. Нужен хотя бы один источник ключей:
+// секрет HS256, PEM-файл или JWKS-файл с ключами RS256
+type AuthConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	HS256Secret   string `yaml:"hs256_secret"`
+	PublicKeyFile string `yaml:"public_key_file"`
+	JWKSFile      string `yaml:"jwks_file"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	AdminRole     string `yaml:"admin_role"`
+}
+
+// AdminConfig - административные эндпоинты. AllowUnauthenticated открывает
+// их всем при выключенной аутентификации, иначе они отвечают 403
+type AdminConfig struct {
+	AllowUnauthenticated bool `yaml:"allow_unauthenticated"`
+}
+
+// TracingConfig - экспорт трейсов: none, stdout, file или otlp
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+	File     string `yaml:"file"`
+}
+
+// LogConfig - уровень логирования: debug, info, warn, error
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+type ReadinessConfig struct {
+	Timeout         time.Duration `yaml:"timeout"`
+	GRPCHealthCheck bool          `yaml:"grpc_health_check"`
 }
 
-func Load() *Config {
+type RateLimitConfig struct {
+	Enabled bool      `yaml:"enabled"`
+	Menu    RateLimit `yaml:"menu"`
+	Orders  RateLimit `yaml:"orders"`
+	// APIKeys - ключи клиентов для заголовка X-API-Key по id клиента.
+	// Запросы с известным ключом считаются по клиенту, а не по IP
+	APIKeys map[string]string `yaml:"api_keys"`
+}
+
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// GraphQLConfig - эндпоинт /graphql. Лимиты глубины и сложности запроса,
+// 0 - без ограничения
+type GraphQLConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	MaxDepth      int  `yaml:"max_depth"`
+	MaxComplexity int  `yaml:"max_complexity"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// CertReloadConfig - период проверки файлов сертификатов на изменения,
+// 0 отключает перезагрузку
+type CertReloadConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+func Default() *Config {
 	return &Config{
-		MenuServiceAddr:  getEnv("MENU_SERVICE_ADDR", "menu-service:50051"),
-		OrderServiceAddr: getEnv("ORDER_SERVICE_ADDR", "order-service:50052"),
-		ServerPort:       getEnv("SERVER_PORT", "8080"),
+		Server: ServerConfig{
+			Port:               "8080",
+			ReadTimeout:        10 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        2 * time.Minute,
+			ShutdownTimeout:    5 * time.Second,
+			ShutdownDrainDelay: 5 * time.Second,
+		},
+		Menu:  BackendConfig{Addr: "menu-service:50051"},
+		Order: BackendConfig{Addr: "order-service:50052"},
+		GRPC: GRPCConfig{
+			Timeout:        3 * time.Second,
+			MethodTimeouts: map[string]time.Duration{},
+			MaxRetries:     2,
+			RetryBackoff:   100 * time.Millisecond,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      10 * time.Second,
+		},
+		Cache: CacheConfig{
+			MenuTTL:      5 * time.Minute,
+			MenuStaleTTL: time.Hour,
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour},
+		OrderEvents: OrderEventsConfig{Heartbeat: 15 * time.Second},
+		Cart:        CartConfig{TTL: 7 * 24 * time.Hour},
+		Pricing: PricingConfig{
+			Currency:         "RUB",
+			TaxRate:          "0",
+			CategoryTaxRates: map[int32]string{},
+		},
+		Auth: AuthConfig{
+			Enabled:   true,
+			AdminRole: "admin",
+		},
+		Tracing: TracingConfig{
+			Exporter: "none",
+			File:     "traces.jsonl",
+		},
+		Log: LogConfig{Level: "info"},
+		Readiness: ReadinessConfig{
+			Timeout: time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Menu:    RateLimit{RequestsPerSecond: 20, Burst: 40},
+			Orders:  RateLimit{RequestsPerSecond: 5, Burst: 10},
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:       true,
+			MaxDepth:      10,
+			MaxComplexity: 5000,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "Idempotency-Key", "If-None-Match", "X-Request-ID", "X-API-Key"},
+			MaxAge:         10 * time.Minute,
+		},
+		CertReload: CertReloadConfig{Interval: 30 * time.Second},
+	}
+}
+
+// Load читает конфигурацию и проверяет её. Все найденные ошибки
+// возвращаются разом, по одной на строку. Для -help справка уже выведена, и
+// возвращается flag.ErrHelp.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("bff-service", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML config file")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	overrides := defineFlags(fs, cfg)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	errs = append(errs, applyEnv(cfg)...)
+
+	overrides.apply()
+
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
 	}
+	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ValidationError содержит все ошибки конфигурации.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msg := "invalid configuration:"
+	for _, err := range e.Errors {
+		msg += "\n  - " + err.Error()
+	}
+	return msg
+}
+
+const redacted = "REDACTED"
+
+// Print выводит итоговую конфигурацию в YAML, скрывая секреты.
+func (c *Config) Print(w io.Writer) error {
+	out := *c
+	if out.Auth.HS256Secret != "" {
+		out.Auth.HS256Secret = redacted
+	}
+	if len(out.RateLimit.APIKeys) > 0 {
+		keys := make(map[string]string, len(out.RateLimit.APIKeys))
+		for id := range out.RateLimit.APIKeys {
+			keys[id] = redacted
+		}
+		out.RateLimit.APIKeys = keys
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&out); err != nil {
+		return err
 	}
-	return defaultValue
+	return enc.Close()
 }
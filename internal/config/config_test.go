@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig записывает YAML во временный файл и сбрасывает CONFIG_FILE,
+// чтобы окружение процесса не влияло на тест.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	t.Setenv("CONFIG_FILE", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  port: "9000"
+menu:
+  addr: menu.internal:50051
+grpc:
+  timeout: 5s
+  max_retries: 4
+log:
+  level: debug
+auth:
+  hs256_secret: secret
+`)
+	t.Setenv("SERVER_PORT", "9100")
+	t.Setenv("GRPC_TIMEOUT", "7s")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := Load([]string{"-config", path, "-port", "9200", "-grpc-max-retries", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"default", cfg.Cart.TTL, 7 * 24 * time.Hour},
+		{"file over default", cfg.Menu.Addr, "menu.internal:50051"},
+		{"env over file", cfg.GRPC.Timeout, 7 * time.Second},
+		{"env without flag", cfg.Log.Level, "warn"},
+		{"flag over env", cfg.Server.Port, "9200"},
+		{"zero flag over file", cfg.GRPC.MaxRetries, 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  prot: "9000"
+auth:
+  hs256_secret: secret
+`)
+
+	_, err := Load([]string{"-config", path})
+	if err == nil || !strings.Contains(err.Error(), "field prot not found") {
+		t.Fatalf("err = %v, want unknown field prot", err)
+	}
+}
+
+func TestLoadCollectsErrors(t *testing.T) {
+	path := writeConfig(t, `
+pricing:
+  currency: rubles
+auth:
+  enabled: true
+`)
+	t.Setenv("GRPC_TIMEOUT", "soon")
+	t.Setenv("RATE_LIMIT_MENU_BURST", "many")
+
+	_, err := Load([]string{"-config", path, "-breaker-failure-threshold", "0"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want ValidationError", err)
+	}
+
+	want := []string{
+		"GRPC_TIMEOUT",
+		"RATE_LIMIT_MENU_BURST",
+		"breaker.failure_threshold",
+		"pricing.currency",
+		"auth: enabled",
+	}
+	if len(verr.Errors) != len(want) {
+		t.Fatalf("errors = %v, want %d", verr.Errors, len(want))
+	}
+	for _, field := range want {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error does not mention %s:\n%v", field, err)
+		}
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+
+	// Справку флаги печатают в stderr, тесту достаточно ошибки
+	stderr := os.Stderr
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stderr = devNull
+	_, err = Load([]string{"-help"})
+	os.Stderr = stderr
+
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("err = %v, want flag.ErrHelp", err)
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv переопределяет значения переменными окружения и возвращает
+// ошибки разбора для всех некорректных переменных.
+func applyEnv(cfg *Config) []error {
+	e := &envReader{}
+
+	e.str("SERVER_PORT", &cfg.Server.Port)
+	e.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	e.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	e.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	e.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	e.duration("SHUTDOWN_DRAIN_DELAY", &cfg.Server.ShutdownDrainDelay)
+	e.str("SERVER_TLS_CERT_FILE", &cfg.Server.TLS.CertFile)
+	e.str("SERVER_TLS_KEY_FILE", &cfg.Server.TLS.KeyFile)
+	e.str("SERVER_TLS_CLIENT_CA_FILE", &cfg.Server.TLS.ClientCAFile)
+	e.list("SERVER_TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	e.backend("MENU", &cfg.Menu)
+	e.backend("ORDER", &cfg.Order)
+
+	e.duration("GRPC_TIMEOUT", &cfg.GRPC.Timeout)
+	e.durationMap("GRPC_METHOD_TIMEOUTS", &cfg.GRPC.MethodTimeouts)
+	e.int("GRPC_MAX_RETRIES", &cfg.GRPC.MaxRetries)
+	e.duration("GRPC_RETRY_BACKOFF", &cfg.GRPC.RetryBackoff)
+
+	e.int("BREAKER_FAILURE_THRESHOLD", &cfg.Breaker.FailureThreshold)
+	e.duration("BREAKER_OPEN_TIMEOUT", &cfg.Breaker.OpenTimeout)
+
+	e.duration("MENU_CACHE_TTL", &cfg.Cache.MenuTTL)
+	e.duration("MENU_CACHE_STALE_TTL", &cfg.Cache.MenuStaleTTL)
+
+	e.duration("IDEMPOTENCY_TTL", &cfg.Idempotency.TTL)
+
+	e.duration("CART_TTL", &cfg.Cart.TTL)
+
+	e.str("PRICING_CURRENCY", &cfg.Pricing.Currency)
+	e.str("PRICING_TAX_RATE", &cfg.Pricing.TaxRate)
+	e.rateMap("PRICING_CATEGORY_TAX_RATES", &cfg.Pricing.CategoryTaxRates)
+
+	e.duration("ORDER_EVENTS_HEARTBEAT", &cfg.OrderEvents.Heartbeat)
+
+	e.bool("AUTH_ENABLED", &cfg.Auth.Enabled)
+	e.str("JWT_HS256_SECRET", &cfg.Auth.HS256Secret)
+	e.str("JWT_PUBLIC_KEY_FILE", &cfg.Auth.PublicKeyFile)
+	e.str("JWT_JWKS_FILE", &cfg.Auth.JWKSFile)
+	e.str("JWT_ISSUER", &cfg.Auth.Issuer)
+	e.str("JWT_AUDIENCE", &cfg.Auth.Audience)
+	e.str("JWT_ADMIN_ROLE", &cfg.Auth.AdminRole)
+
+	e.bool("ADMIN_ALLOW_UNAUTHENTICATED", &cfg.Admin.AllowUnauthenticated)
+
+	e.str("OTEL_TRACES_EXPORTER", &cfg.Tracing.Exporter)
+	e.str("OTEL_TRACES_FILE", &cfg.Tracing.File)
+
+	e.str("LOG_LEVEL", &cfg.Log.Level)
+
+	e.duration("READINESS_TIMEOUT", &cfg.Readiness.Timeout)
+	e.bool("READINESS_GRPC_HEALTH_CHECK", &cfg.Readiness.GRPCHealthCheck)
+
+	e.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	e.float("RATE_LIMIT_MENU_RPS", &cfg.RateLimit.Menu.RequestsPerSecond)
+	e.int("RATE_LIMIT_MENU_BURST", &cfg.RateLimit.Menu.Burst)
+	e.float("RATE_LIMIT_ORDERS_RPS", &cfg.RateLimit.Orders.RequestsPerSecond)
+	e.int("RATE_LIMIT_ORDERS_BURST", &cfg.RateLimit.Orders.Burst)
+	e.stringMap("RATE_LIMIT_API_KEYS", &cfg.RateLimit.APIKeys)
+
+	e.bool("GRAPHQL_ENABLED", &cfg.GraphQL.Enabled)
+	e.int("GRAPHQL_MAX_DEPTH", &cfg.GraphQL.MaxDepth)
+	e.int("GRAPHQL_MAX_COMPLEXITY", &cfg.GraphQL.MaxComplexity)
+
+	e.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	e.list("CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	e.list("CORS_ALLOWED_HEADERS", &cfg.CORS.AllowedHeaders)
+	e.bool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	e.duration("CORS_MAX_AGE", &cfg.CORS.MaxAge)
+
+	e.duration("CERT_RELOAD_INTERVAL", &cfg.CertReload.Interval)
+
+	return e.errs
+}
+
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) fail(key, value, expected string) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %q is not a valid %s", key, value, expected))
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if value, ok := os.LookupEnv(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, value, "integer")
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) float(key string, dst *float64) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.fail(key, value, "number")
+		return
+	}
+	*dst = f
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value, "boolean")
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, value, "duration")
+		return
+	}
+	*dst = d
+}
+
+// list разбирает значения через запятую.
+func (e *envReader) list(key string, dst *[]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+// durationMap разбирает значения вида "GetDishes=1s,CreateOrder=5s".
+func (e *envReader) durationMap(key string, dst *map[string]time.Duration) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, raw, found := strings.Cut(pair, "=")
+		d, err := time.ParseDuration(raw)
+		if !found || err != nil {
+			e.fail(key, pair, "name=duration pair")
+			continue
+		}
+		result[name] = d
+	}
+	*dst = result
+}
+
+// rateMap разбирает ставки по категориям вида "3=10,4=0".
+func (e *envReader) rateMap(key string, dst *map[int32]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	result := make(map[int32]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		rawID, rate, found := strings.Cut(pair, "=")
+		id, err := strconv.ParseInt(strings.TrimSpace(rawID), 10, 32)
+		if !found || err != nil {
+			e.fail(key, pair, "category=rate pair")
+			continue
+		}
+		result[int32(id)] = strings.TrimSpace(rate)
+	}
+	*dst = result
+}
+
+// stringMap разбирает значения вида "partner=key1,mobile=key2".
+func (e *envReader) stringMap(key string, dst *map[string]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, item, found := strings.Cut(pair, "=")
+		if !found {
+			// Значение может быть секретом, в ошибку попадает только имя
+			e.fail(key, name, "name=value pair")
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(item)
+	}
+	*dst = result
+}
+
+func (e *envReader) backend(prefix string, dst *BackendConfig) {
+	e.str(prefix+"_SERVICE_ADDR", &dst.Addr)
+	e.bool(prefix+"_TLS_ENABLED", &dst.TLS.Enabled)
+	e.str(prefix+"_TLS_CA_FILE", &dst.TLS.CAFile)
+	e.str(prefix+"_TLS_CERT_FILE", &dst.TLS.CertFile)
+	e.str(prefix+"_TLS_KEY_FILE", &dst.TLS.KeyFile)
+	e.str(prefix+"_TLS_SERVER_NAME", &dst.TLS.ServerName)
+}
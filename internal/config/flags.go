@@ -0,0 +1,113 @@
+package config
+
+import (
+	"flag"
+	"time"
+)
+
+// flagOverrides - флаги, переопределяющие скалярные настройки. Значения
+// применяются после файла и переменных окружения и только для флагов,
+// заданных явно. Списки, словари, пути к TLS-файлам и секреты задаются
+// только в YAML или окружении.
+type flagOverrides struct {
+	fs      *flag.FlagSet
+	setters map[string]func()
+}
+
+func defineFlags(fs *flag.FlagSet, cfg *Config) *flagOverrides {
+	f := &flagOverrides{fs: fs, setters: make(map[string]func())}
+
+	f.str("port", "HTTP listen port", &cfg.Server.Port)
+	f.duration("read-timeout", "HTTP server read timeout", &cfg.Server.ReadTimeout)
+	f.duration("write-timeout", "HTTP server write timeout", &cfg.Server.WriteTimeout)
+	f.duration("idle-timeout", "HTTP server idle timeout", &cfg.Server.IdleTimeout)
+	f.duration("shutdown-timeout", "graceful shutdown timeout", &cfg.Server.ShutdownTimeout)
+	f.duration("shutdown-drain-delay", "delay between failing readiness and closing the listener", &cfg.Server.ShutdownDrainDelay)
+
+	f.str("menu-addr", "menu service gRPC address", &cfg.Menu.Addr)
+	f.str("order-addr", "order service gRPC address", &cfg.Order.Addr)
+
+	f.duration("grpc-timeout", "default timeout of backend calls", &cfg.GRPC.Timeout)
+	f.int("grpc-max-retries", "retries of idempotent backend calls", &cfg.GRPC.MaxRetries)
+	f.duration("grpc-retry-backoff", "initial backoff between retries", &cfg.GRPC.RetryBackoff)
+
+	f.int("breaker-failure-threshold", "consecutive failures that open the circuit breaker", &cfg.Breaker.FailureThreshold)
+	f.duration("breaker-open-timeout", "time the circuit breaker stays open", &cfg.Breaker.OpenTimeout)
+
+	f.duration("menu-cache-ttl", "menu cache TTL, 0 disables the cache", &cfg.Cache.MenuTTL)
+	f.duration("menu-cache-stale-ttl", "how long stale menu entries may be served", &cfg.Cache.MenuStaleTTL)
+
+	f.duration("idempotency-ttl", "how long idempotent responses are kept", &cfg.Idempotency.TTL)
+	f.duration("cart-ttl", "how long unchanged carts are kept", &cfg.Cart.TTL)
+
+	f.str("pricing-currency", "default currency of dish prices", &cfg.Pricing.Currency)
+	f.str("pricing-tax-rate", "default tax rate in percent", &cfg.Pricing.TaxRate)
+
+	f.duration("order-events-heartbeat", "heartbeat interval of order event streams", &cfg.OrderEvents.Heartbeat)
+
+	f.bool("auth-enabled", "require JWT authentication", &cfg.Auth.Enabled)
+	f.str("jwt-issuer", "required JWT issuer", &cfg.Auth.Issuer)
+	f.str("jwt-audience", "required JWT audience", &cfg.Auth.Audience)
+	f.str("jwt-admin-role", "JWT role with access to all users and admin routes", &cfg.Auth.AdminRole)
+
+	f.bool("admin-allow-unauthenticated", "open admin routes to everyone when authentication is disabled", &cfg.Admin.AllowUnauthenticated)
+
+	f.str("tracing-exporter", "trace exporter: none, stdout, file, otlp", &cfg.Tracing.Exporter)
+	f.str("tracing-file", "trace file for the file exporter", &cfg.Tracing.File)
+
+	f.str("log-level", "log level: debug, info, warn, error", &cfg.Log.Level)
+
+	f.duration("readiness-timeout", "timeout of readiness checks", &cfg.Readiness.Timeout)
+	f.bool("readiness-grpc-health-check", "use the gRPC health protocol for readiness checks", &cfg.Readiness.GRPCHealthCheck)
+
+	f.bool("rate-limit-enabled", "enable rate limiting", &cfg.RateLimit.Enabled)
+	f.float("rate-limit-menu-rps", "menu requests per second per client", &cfg.RateLimit.Menu.RequestsPerSecond)
+	f.int("rate-limit-menu-burst", "menu request burst per client", &cfg.RateLimit.Menu.Burst)
+	f.float("rate-limit-orders-rps", "order requests per second per client", &cfg.RateLimit.Orders.RequestsPerSecond)
+	f.int("rate-limit-orders-burst", "order request burst per client", &cfg.RateLimit.Orders.Burst)
+
+	f.bool("graphql-enabled", "enable the /graphql endpoint", &cfg.GraphQL.Enabled)
+	f.int("graphql-max-depth", "maximum GraphQL query depth, 0 - unlimited", &cfg.GraphQL.MaxDepth)
+	f.int("graphql-max-complexity", "maximum GraphQL query complexity, 0 - unlimited", &cfg.GraphQL.MaxComplexity)
+
+	f.bool("cors-allow-credentials", "allow credentials in CORS requests", &cfg.CORS.AllowCredentials)
+	f.duration("cors-max-age", "CORS preflight cache duration", &cfg.CORS.MaxAge)
+
+	f.duration("cert-reload-interval", "certificate reload check interval, 0 disables reloading", &cfg.CertReload.Interval)
+
+	return f
+}
+
+// apply переопределяет настройки флагами, заданными в командной строке.
+func (f *flagOverrides) apply() {
+	f.fs.Visit(func(fl *flag.Flag) {
+		if set, ok := f.setters[fl.Name]; ok {
+			set()
+		}
+	})
+}
+
+func (f *flagOverrides) str(name, usage string, dst *string) {
+	value := f.fs.String(name, "", usage)
+	f.setters[name] = func() { *dst = *value }
+}
+
+func (f *flagOverrides) int(name, usage string, dst *int) {
+	value := f.fs.Int(name, 0, usage)
+	f.setters[name] = func() { *dst = *value }
+}
+
+func (f *flagOverrides) float(name, usage string, dst *float64) {
+	value := f.fs.Float64(name, 0, usage)
+	f.setters[name] = func() { *dst = *value }
+}
+
+func (f *flagOverrides) bool(name, usage string, dst *bool) {
+	value := f.fs.Bool(name, false, usage)
+	f.setters[name] = func() { *dst = *value }
+}
+
+func (f *flagOverrides) duration(name, usage string, dst *time.Duration) {
+	value := f.fs.Duration(name, 0, usage)
+	f.setters[name] = func() { *dst = *value }
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/pricing"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var tracingExporters = map[string]bool{"none": true, "stdout": true, "file": true, "otlp": true}
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки.
+func (c *Config) Validate() []error {
+	v := &validator{}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		v.add("server.port", "must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	v.nonNegative("server.shutdown_drain_delay", c.Server.ShutdownDrainDelay)
+	v.keyPair("server.tls", c.Server.TLS.CertFile, c.Server.TLS.KeyFile)
+	v.file("server.tls.client_ca_file", c.Server.TLS.ClientCAFile)
+	if c.Server.TLS.ClientCAFile != "" && c.Server.TLS.CertFile == "" {
+		v.add("server.tls.client_ca_file", "requires server.tls.cert_file and server.tls.key_file")
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				v.add("server.trusted_proxies", "must contain IP addresses or CIDR subnets, got %q", proxy)
+			}
+		}
+	}
+
+	v.backend("menu", c.Menu)
+	v.backend("order", c.Order)
+
+	v.positive("grpc.timeout", c.GRPC.Timeout)
+	for method, timeout := range c.GRPC.MethodTimeouts {
+		v.positive("grpc.method_timeouts."+method, timeout)
+	}
+	if c.GRPC.MaxRetries < 0 {
+		v.add("grpc.max_retries", "must not be negative")
+	}
+	v.nonNegative("grpc.retry_backoff", c.GRPC.RetryBackoff)
+
+	if c.Breaker.FailureThreshold < 1 {
+		v.add("breaker.failure_threshold", "must be at least 1")
+	}
+	v.positive("breaker.open_timeout", c.Breaker.OpenTimeout)
+
+	v.nonNegative("cache.menu_ttl", c.Cache.MenuTTL)
+	v.nonNegative("cache.menu_stale_ttl", c.Cache.MenuStaleTTL)
+
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+
+	v.positive("cart.ttl", c.Cart.TTL)
+
+	if !currencyCode.MatchString(c.Pricing.Currency) {
+		v.add("pricing.currency", "must be an ISO 4217 code like RUB, got %q", c.Pricing.Currency)
+	}
+	if _, err := pricing.ParseRate(c.Pricing.TaxRate); err != nil {
+		v.add("pricing.tax_rate", "%v", err)
+	}
+	for category, rate := range c.Pricing.CategoryTaxRates {
+		if _, err := pricing.ParseRate(rate); err != nil {
+			v.add(fmt.Sprintf("pricing.category_tax_rates.%d", category), "%v", err)
+		}
+	}
+
+	if c.Auth.Enabled {
+		if c.Auth.HS256Secret == "" && c.Auth.PublicKeyFile == "" && c.Auth.JWKSFile == "" {
+			v.add("auth", "enabled but none of hs256_secret, public_key_file or jwks_file is set")
+		}
+		v.file("auth.public_key_file", c.Auth.PublicKeyFile)
+		v.file("auth.jwks_file", c.Auth.JWKSFile)
+	}
+
+	if !tracingExporters[c.Tracing.Exporter] {
+		v.add("tracing.exporter", "must be one of none, stdout, file, otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Exporter == "file" && c.Tracing.File == "" {
+		v.add("tracing.file", "is required for the file exporter")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		v.add("log.level", "must be one of debug, info, warn, error, got %q", c.Log.Level)
+	}
+
+	v.positive("order_events.heartbeat", c.OrderEvents.Heartbeat)
+
+	v.positive("readiness.timeout", c.Readiness.Timeout)
+
+	if c.RateLimit.Enabled {
+		v.rateLimit("rate_limit.menu", c.RateLimit.Menu)
+		v.rateLimit("rate_limit.orders", c.RateLimit.Orders)
+		v.apiKeys("rate_limit.api_keys", c.RateLimit.APIKeys)
+	}
+
+	if c.GraphQL.MaxDepth < 0 {
+		v.add("graphql.max_depth", "must not be negative")
+	}
+	if c.GraphQL.MaxComplexity < 0 {
+		v.add("graphql.max_complexity", "must not be negative")
+	}
+
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if origin == "*" {
+				v.add("cors.allowed_origins", "wildcard origin cannot be combined with allow_credentials")
+			}
+		}
+	}
+	v.nonNegative("cors.max_age", c.CORS.MaxAge)
+
+	v.nonNegative("cert_reload.interval", c.CertReload.Interval)
+
+	return v.errs
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	if d <= 0 {
+		v.add(field, "must be positive")
+	}
+}
+
+func (v *validator) nonNegative(field string, d time.Duration) {
+	if d < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validator) file(field, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			v.add(field, "file %s does not exist", path)
+		} else {
+			v.add(field, "cannot access %s: %v", path, err)
+		}
+	}
+}
+
+func (v *validator) keyPair(field, certFile, keyFile string) {
+	if (certFile == "") != (keyFile == "") {
+		v.add(field, "cert_file and key_file must be set together")
+	}
+	v.file(field+".cert_file", certFile)
+	v.file(field+".key_file", keyFile)
+}
+
+func (v *validator) backend(name string, b BackendConfig) {
+	if b.Addr == "" {
+		v.add(name+".addr", "is required")
+	}
+	if !b.TLS.Enabled {
+		if b.TLS.CAFile != "" || b.TLS.CertFile != "" || b.TLS.KeyFile != "" {
+			v.add(name+".tls", "certificate files are set but tls.enabled is false")
+		}
+		return
+	}
+	v.file(name+".tls.ca_file", b.TLS.CAFile)
+	v.keyPair(name+".tls", b.TLS.CertFile, b.TLS.KeyFile)
+}
+
+func (v *validator) rateLimit(field string, l RateLimit) {
+	if l.RequestsPerSecond <= 0 {
+		v.add(field+".requests_per_second", "must be positive")
+	}
+	if l.Burst < 1 {
+		v.add(field+".burst", "must be at least 1")
+	}
+}
+
+func (v *validator) apiKeys(field string, keys map[string]string) {
+	owners := make(map[string]string, len(keys))
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	// Порядок ошибок не зависит от порядка обхода словаря
+	sort.Strings(ids)
+	for _, id := range ids {
+		key := keys[id]
+		switch {
+		case id == "":
+			v.add(field, "client id must not be empty")
+		case key == "":
+			v.add(field+"."+id, "must not be empty")
+		case owners[key] != "":
+			v.add(field+"."+id, "duplicates the key of client %q", owners[key])
+		default:
+			owners[key] = id
+		}
+	}
+}
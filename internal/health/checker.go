@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var errNotReady = errors.New("connection is not ready")
+
+type Dependency struct {
+	Name string
+	Conn *grpc.ClientConn
+}
+
+type DependencyStatus struct {
+	Healthy bool   `json:"healthy"`
+	State   string `json:"state"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Ready        bool                        `json:"ready"`
+	ShuttingDown bool                        `json:"shutting_down,omitempty"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Checker определяет готовность сервиса принимать трафик по состоянию
+// соединений с бэкендами и, если включено, по стандартному gRPC health check.
+type Checker struct {
+	deps              []Dependency
+	timeout           time.Duration
+	useHealthProtocol bool
+
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration, useHealthProtocol bool, deps ...Dependency) *Checker {
+	return &Checker{
+		deps:              deps,
+		timeout:           timeout,
+		useHealthProtocol: useHealthProtocol,
+	}
+}
+
+// SetShuttingDown переводит сервис в состояние "не готов" на время
+// корректного завершения, чтобы балансировщик успел убрать его из ротации.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Ready:        true,
+		ShuttingDown: c.shuttingDown.Load(),
+		Dependencies: make(map[string]DependencyStatus, len(c.deps)),
+	}
+	if report.ShuttingDown {
+		report.Ready = false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dep := range c.deps {
+		wg.Add(1)
+		go func(dep Dependency) {
+			defer wg.Done()
+			st := c.checkDependency(ctx, dep)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[dep.Name] = st
+			if !st.Healthy {
+				report.Ready = false
+			}
+		}(dep)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) checkDependency(ctx context.Context, dep Dependency) DependencyStatus {
+	if err := waitForReady(ctx, dep.Conn); err != nil {
+		return DependencyStatus{State: dep.Conn.GetState().String(), Error: errNotReady.Error()}
+	}
+
+	st := DependencyStatus{Healthy: true, State: connectivity.Ready.String()}
+	if !c.useHealthProtocol {
+		return st
+	}
+
+	resp, err := healthpb.NewHealthClient(dep.Conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		// Бэкенд не реализует health protocol, достаточно готового соединения
+	case err != nil:
+		st.Healthy = false
+		st.Error = status.Convert(err).Message()
+	case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+		st.Healthy = false
+		st.Error = "backend reports " + resp.GetStatus().String()
+	}
+	return st
+}
+
+// waitForReady ждёт перехода соединения в READY. Соединения создаются
+// лениво, поэтому простаивающее соединение сначала запускается.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			conn.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return errNotReady
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
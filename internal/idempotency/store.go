@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInProgress - запрос с этим ключом ещё выполняется
+	ErrInProgress = errors.New("request with this idempotency key is in progress")
+	// ErrFingerprintMismatch - ключ уже использован с другим телом запроса
+	ErrFingerprintMismatch = errors.New("idempotency key was used with a different request")
+)
+
+// Record - сохранённый результат запроса, который отдаётся при повторах.
+type Record struct {
+	Fingerprint string
+	StatusCode  int
+	Body        []byte
+}
+
+// Store хранит ключи идемпотентности. Реализация в памяти подходит для
+// одного экземпляра сервиса, для нескольких нужен общий бэкенд.
+type Store interface {
+	// Begin резервирует ключ за запросом с отпечатком fingerprint. Если по
+	// ключу уже есть завершённый результат, он возвращается без резервирования.
+	// Резервирование ограничено по времени, чтобы ключ освободился, даже если
+	// запрос не вызвал ни Complete, ни Release.
+	Begin(ctx context.Context, key, fingerprint string) (*Record, error)
+	// Complete сохраняет результат запроса и снимает резервирование.
+	Complete(ctx context.Context, key string, record Record) error
+	// Release снимает резервирование без сохранения результата, чтобы
+	// запрос можно было повторить.
+	Release(ctx context.Context, key string) error
+}
+
+type memoryEntry struct {
+	record    Record
+	completed bool
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	ttl   time.Duration
+	lease time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore создаёт хранилище, в котором результаты живут ttl, а
+// резервирование выполняющегося запроса - lease. lease должен быть не меньше
+// времени выполнения запроса.
+func NewMemoryStore(ttl, lease time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:       ttl,
+		lease:     lease,
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Begin(_ context.Context, key, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.record.Fingerprint != fingerprint {
+			return nil, ErrFingerprintMismatch
+		}
+		if !entry.completed {
+			return nil, ErrInProgress
+		}
+		record := entry.record
+		return &record, nil
+	}
+
+	s.entries[key] = &memoryEntry{
+		record:    Record{Fingerprint: fingerprint},
+		expiresAt: now.Add(s.lease),
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryEntry{
+		record:    record,
+		completed: true,
+		expiresAt: time.Now().Add(s.ttl),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.completed {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// sweep удаляет просроченные записи не чаще раза в минуту.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
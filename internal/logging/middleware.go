@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// Ключ метаданных gRPC, в котором request id уходит в бэкенды
+	RequestIDMetadataKey = "x-request-id"
+)
+
+// RequestID принимает X-Request-ID клиента или генерирует новый, возвращает
+// его в ответе и кладёт в контекст запроса для логов и вызовов бэкендов.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !ValidRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog пишет по одной структурированной записи на HTTP-запрос.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		slog.Log(c.Request.Context(), level, "http request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("response_size", c.Writer.Size()),
+		)
+	}
+}
+
+// UnaryClientInterceptor передаёт request id в метаданных вызова и логирует
+// длительность и статус каждого вызова бэкенда.
+func UnaryClientInterceptor(backend string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = outgoingContext(ctx)
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "grpc call",
+			slog.String("backend", backend),
+			slog.String("method", path.Base(method)),
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return err
+	}
+}
+
+// StreamClientInterceptor передаёт request ID в потоковые вызовы и пишет в
+// лог их открытие.
+func StreamClientInterceptor(backend string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = outgoingContext(ctx)
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "grpc stream opened",
+			slog.String("backend", backend),
+			slog.String("method", path.Base(method)),
+			slog.String("code", status.Code(err).String()),
+		)
+		return stream, err
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/client"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "bff"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	grpcInFlight *prometheus.GaugeVec
+
+	streamRequests *prometheus.CounterVec
+	streamDuration *prometheus.HistogramVec
+	streamInFlight *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_requests_total",
+			Help:      "Outgoing gRPC calls by backend, method and status code.",
+		}, []string{"backend", "method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_request_duration_seconds",
+			Help:      "Outgoing gRPC call latency including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"backend", "method", "code"}),
+		grpcInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_requests_in_flight",
+			Help:      "Outgoing gRPC calls currently in progress.",
+		}, []string{"backend", "method"}),
+
+		// Потоки живут минутами, поэтому их длительность учитывается
+		// отдельно от задержки обычных вызовов
+		streamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_streams_total",
+			Help:      "Finished outgoing gRPC streams by backend, method and status code.",
+		}, []string{"backend", "method", "code"}),
+		streamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_stream_duration_seconds",
+			Help:      "Lifetime of outgoing gRPC streams.",
+			Buckets:   []float64{0.1, 1, 10, 60, 300, 900, 1800, 3600},
+		}, []string{"backend", "method", "code"}),
+		streamInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "grpc_client_streams_in_flight",
+			Help:      "Outgoing gRPC streams currently open.",
+		}, []string{"backend", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.grpcInFlight,
+		m.streamRequests,
+		m.streamDuration,
+		m.streamInFlight,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// GinMiddleware учитывает запросы по шаблону маршрута (/orders/:id), а не по
+// фактическому пути, чтобы число рядов не зависело от id в URL.
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.httpInFlight.Inc()
+		defer m.httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(c.Writer.Status()),
+		}
+		m.httpRequests.With(labels).Inc()
+		m.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) UnaryClientInterceptor(backend string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		name := path.Base(method)
+		inFlight := m.grpcInFlight.WithLabelValues(backend, name)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		code := status.Code(err).String()
+		m.grpcRequests.WithLabelValues(backend, name, code).Inc()
+		m.grpcDuration.WithLabelValues(backend, name, code).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// StreamClientInterceptor учитывает потоковые вызовы от открытия до
+// завершения. Поток считается завершённым, когда RecvMsg вернул ошибку
+// (io.EOF - успешное завершение) или вызывающий отменил контекст.
+func (m *Metrics) StreamClientInterceptor(backend string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		name := path.Base(method)
+		inFlight := m.streamInFlight.WithLabelValues(backend, name)
+		inFlight.Inc()
+		start := time.Now()
+
+		var once sync.Once
+		finish := func(err error) {
+			once.Do(func() {
+				inFlight.Dec()
+				code := status.Code(err)
+				if errors.Is(err, io.EOF) {
+					code = codes.OK
+				}
+				m.streamRequests.WithLabelValues(backend, name, code.String()).Inc()
+				m.streamDuration.WithLabelValues(backend, name, code.String()).Observe(time.Since(start).Seconds())
+			})
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+		// Контекст самого потока gRPC отменяет и при обычном завершении,
+		// поэтому отслеживается контекст вызывающего
+		stop := context.AfterFunc(ctx, func() {
+			finish(status.FromContextError(ctx.Err()).Err())
+		})
+		return &meteredStream{ClientStream: stream, finish: func(err error) {
+			stop()
+			finish(err)
+		}}, nil
+	}
+}
+
+type meteredStream struct {
+	grpc.ClientStream
+	finish func(error)
+}
+
+func (s *meteredStream) RecvMsg(msg interface{}) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+// RegisterBreaker публикует состояние circuit breaker:
+// 0 - замкнут, 1 - разомкнут, 2 - пробный режим.
+func (m *Metrics) RegisterBreaker(breaker *client.CircuitBreaker) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "circuit_breaker_state",
+		Help:        "Circuit breaker state: 0 closed, 1 open, 2 half-open.",
+		ConstLabels: prometheus.Labels{"backend": breaker.Name()},
+	}, func() float64 {
+		return float64(breaker.State())
+	}))
+}
+
+func (m *Metrics) RegisterMenuCache(cache *client.MenuCache) {
+	counter := func(name, help string, value func(client.MenuCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "menu_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return float64(value(cache.Stats()))
+		})
+	}
+
+	m.registry.MustRegister(
+		counter("hits_total", "Menu lookups served from a fresh cache entry.",
+			func(s client.MenuCacheStats) uint64 { return s.Hits }),
+		counter("misses_total", "Menu lookups that required a call to the menu service.",
+			func(s client.MenuCacheStats) uint64 { return s.Misses }),
+		counter("stale_total", "Menu lookups served from a stale entry while it is refreshed in the background.",
+			func(s client.MenuCacheStats) uint64 { return s.Stale }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "menu_cache",
+			Name:      "entries",
+			Help:      "Number of entries in the menu cache.",
+		}, func() float64 {
+			return float64(cache.Stats().Entries)
+		}),
+	)
+}
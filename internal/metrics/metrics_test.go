@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/testing/fakes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+)
+
+func TestStreamClientInterceptor(t *testing.T) {
+	backend := fakes.NewServer()
+	defer backend.Close()
+	backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"})
+
+	m := New()
+	conn, err := backend.Dial(grpc.WithChainStreamInterceptor(m.StreamClientInterceptor("order")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	orders := pbOrders.NewOrderServiceClient(conn)
+
+	inFlight := m.streamInFlight.WithLabelValues("order", "WatchOrder")
+	finished := func(code string) float64 {
+		return testutil.ToFloat64(m.streamRequests.WithLabelValues("order", "WatchOrder", code))
+	}
+
+	// Поток открыт, пока вызывающий не отменит контекст
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := orders.WatchOrder(ctx, &pbOrders.WatchOrderRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(inFlight); got != 1 {
+		t.Errorf("streams in flight = %v, want 1", got)
+	}
+	cancel()
+	waitFor(t, func() bool { return finished("Canceled") == 1 })
+	if got := testutil.ToFloat64(inFlight); got != 0 {
+		t.Errorf("streams in flight after cancel = %v, want 0", got)
+	}
+
+	// Ошибка бэкенда учитывается с её кодом и только один раз
+	stream, err = orders.WatchOrder(context.Background(), &pbOrders.WatchOrderRequest{Id: 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Recv(); err == nil {
+			t.Fatal("Recv of a missing order: want error")
+		}
+	}
+	if got := finished("NotFound"); got != 1 {
+		t.Errorf("NotFound streams = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(inFlight); got != 0 {
+		t.Errorf("streams in flight = %v, want 0", got)
+	}
+	if got := testutil.CollectAndCount(m.streamDuration); got != 2 {
+		t.Errorf("stream duration series = %d, want 2", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,202 @@
+// Package openapi строит документ OpenAPI 3.0 из описания маршрутов и Go-типов
+// запросов и ответов.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem - операции одного пути по методу в нижнем регистре.
+type PathItem map[string]*Operation
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Места параметров
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Route описывает маршрут gin. Путь задаётся в синтаксисе gin, например
+// /orders/:id.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	Params      []*Parameter
+	// Body - значение типа тела запроса, nil - запрос без тела
+	Body interface{}
+	// Responses - успешные ответы. Ошибки описываются общим ответом default
+	Responses []RouteResponse
+	// Secured - маршрут требует токен, если включена аутентификация
+	Secured bool
+}
+
+type RouteResponse struct {
+	Status      int
+	Description string
+	// Body - значение типа тела ответа, nil - ответ без тела
+	Body interface{}
+	// ContentType по умолчанию application/json
+	ContentType string
+}
+
+const bearerScheme = "bearerAuth"
+
+// Build строит документ. errorBody - тип тела ответа default для всех
+// операций.
+func Build(info Info, routes []Route, errorBody interface{}) *Document {
+	gen := NewGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+
+	errorResponse := &Response{
+		Description: "Ошибка",
+		Content:     jsonContent(gen.Schema(errorBody)),
+	}
+
+	secured := false
+	for _, r := range routes {
+		op := &Operation{
+			OperationID: r.OperationID,
+			Summary:     r.Summary,
+			Description: r.Description,
+			Parameters:  r.Params,
+			Responses:   map[string]*Response{"default": errorResponse},
+		}
+		if r.Tag != "" {
+			op.Tags = []string{r.Tag}
+		}
+		if r.Body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(gen.Schema(r.Body))}
+		}
+		for _, resp := range r.Responses {
+			description := resp.Description
+			if description == "" {
+				description = http.StatusText(resp.Status)
+			}
+			response := &Response{Description: description}
+			if resp.Body != nil {
+				contentType := resp.ContentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				response.Content = map[string]MediaType{contentType: {Schema: gen.Schema(resp.Body)}}
+			}
+			op.Responses[strconv.Itoa(resp.Status)] = response
+		}
+		if r.Secured {
+			secured = true
+			op.Security = []map[string][]string{{bearerScheme: {}}}
+		}
+
+		path := PathFromGin(r.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	doc.Components.Schemas = gen.Components()
+	if secured {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// PathFromGin переводит путь gin в шаблон OpenAPI: /orders/:id -> /orders/{id}.
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Operations возвращает пары "METHOD /path" всех операций документа в
+// отсортированном виде.
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// Schema - подмножество Schema Object из OpenAPI 3.0, которое нужно для
+// типов API.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Generator строит схемы по Go-типам. Именованные структуры попадают в
+// components/schemas и подставляются ссылками.
+//
+// Поле структуры обязательно, если в теге json нет omitempty. Ограничения
+// задаются тегом openapi через запятую: minimum=1, maximum=99,
+// minLength=1, maxLength=500, minItems=1, maxItems=10, enum=a|b,
+// pattern=^[a-z]+$, format=date-time, nullable. Для элементов среза
+// ограничения задаются с префиксом items., например items.maxLength=64.
+type Generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// Schema возвращает схему типа значения v.
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+func (g *Generator) Components() map[string]*Schema {
+	return g.schemas
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *Generator) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schemaFor(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		// interface{} и прочее - любое значение
+		return &Schema{}
+	}
+}
+
+// ref регистрирует именованную структуру и возвращает ссылку на неё.
+func (g *Generator) ref(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := g.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: schema name %s is used by %s and %s", name, existing, t))
+		}
+	} else {
+		g.types[name] = t
+		// Место резервируется до обхода полей на случай рекурсивных типов
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t, true)
+	return s
+}
+
+// addFields добавляет поля t в s. Поля встроенных структур поднимаются на
+// уровень выше, как при кодировании JSON; поля встроенного указателя
+// необязательны.
+func (g *Generator) addFields(s *Schema, t reflect.Type, required bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, skip := jsonField(f)
+		if skip {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			embeddedRequired := required
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				embeddedRequired = false
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft, embeddedRequired)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		field := g.schemaFor(f.Type)
+		applyTag(field, f.Tag.Get("openapi"))
+		s.Properties[name] = field
+		if required && !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func jsonField(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// applyTag применяет ограничения из тега openapi. Ошибка в теге - ошибка
+// программиста, поэтому она приводит к панике при построении документа.
+func applyTag(s *Schema, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(opt, "=")
+
+		target := s
+		if rest, ok := strings.CutPrefix(key, "items."); ok {
+			if s.Items == nil {
+				panic(fmt.Sprintf("openapi: %s applies to a non-array schema", opt))
+			}
+			target, key = s.Items, rest
+		}
+		if target.Ref != "" || len(target.AllOf) > 0 {
+			panic(fmt.Sprintf("openapi: %s cannot be applied to a schema reference", opt))
+		}
+
+		switch key {
+		case "nullable":
+			target.Nullable = true
+		case "minimum":
+			target.Minimum = float(parseNumber(opt, value))
+		case "maximum":
+			target.Maximum = float(parseNumber(opt, value))
+		case "minLength":
+			target.MinLength = integer(parseInt(opt, value))
+		case "maxLength":
+			target.MaxLength = integer(parseInt(opt, value))
+		case "minItems":
+			target.MinItems = integer(parseInt(opt, value))
+		case "maxItems":
+			target.MaxItems = integer(parseInt(opt, value))
+		case "enum":
+			target.Enum = strings.Split(value, "|")
+		case "pattern":
+			target.Pattern = value
+		case "format":
+			target.Format = value
+		default:
+			panic(fmt.Sprintf("openapi: unknown tag option %q", opt))
+		}
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		// В OpenAPI 3.0 соседние с $ref поля игнорируются
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func parseNumber(opt, value string) float64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: invalid number in %q", opt))
+	}
+	return n
+}
+
+func parseInt(opt, value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: invalid integer in %q", opt))
+	}
+	return n
+}
+
+func float(v float64) *float64 { return &v }
+
+func integer(v int) *int { return &v }
+
+// Helpers для описания параметров
+
+func Integer(format string, minimum, maximum *float64) *Schema {
+	return &Schema{Type: "integer", Format: format, Minimum: minimum, Maximum: maximum}
+}
+
+func Number() *Schema { return &Schema{Type: "number", Format: "double"} }
+
+func String() *Schema { return &Schema{Type: "string"} }
+
+// Bound возвращает указатель на границу для Integer.
+func Bound(v float64) *float64 { return &v }
@@ -0,0 +1,325 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError - ошибка проверки одного поля запроса. Path начинается с места
+// параметра: path.id, query.limit, header.Idempotency-Key,
+// body.order_items[0].quantity.
+type FieldError struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Validator проверяет значения по схемам документа.
+type Validator struct {
+	doc *Document
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+func NewValidator(doc *Document) *Validator {
+	return &Validator{doc: doc, patterns: make(map[string]*regexp.Regexp)}
+}
+
+// ValidateParameter проверяет параметр, переданный строкой. present - был
+// ли параметр в запросе.
+func (v *Validator) ValidateParameter(p *Parameter, raw string, present bool) []FieldError {
+	path := p.In + "." + p.Name
+	if !present {
+		if p.Required {
+			return []FieldError{{Path: path, Reason: "is required"}}
+		}
+		return nil
+	}
+
+	schema := v.resolve(p.Schema)
+	var value interface{} = raw
+	switch schema.Type {
+	case "integer":
+		// В параметрах целые записываются только цифрами, как их разбирает
+		// strconv.ParseInt
+		if !integerPattern.MatchString(raw) {
+			return []FieldError{{Path: path, Reason: "must be an integer"}}
+		}
+		value = json.Number(raw)
+	case "number":
+		if !numberPattern.MatchString(raw) {
+			return []FieldError{{Path: path, Reason: "must be a number"}}
+		}
+		value = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []FieldError{{Path: path, Reason: "must be a boolean"}}
+		}
+		value = b
+	}
+	return v.ValidateValue(p.Schema, value, path)
+}
+
+// ValidateValue проверяет значение, декодированное из JSON. Числа могут быть
+// json.Number или float64.
+func (v *Validator) ValidateValue(s *Schema, value interface{}, path string) []FieldError {
+	var errs []FieldError
+	v.validate(s, value, path, &errs)
+	return errs
+}
+
+func (v *Validator) resolve(s *Schema) *Schema {
+	for s.Ref != "" {
+		s = v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, schemaRefPrefix)]
+	}
+	return s
+}
+
+func (v *Validator) validate(s *Schema, value interface{}, path string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Path: path, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		// Схема без типа допускает любое значение, в том числе null
+		if !s.Nullable && (s.Type != "" || s.Ref != "" || len(s.AllOf) > 0) {
+			fail("must not be null")
+		}
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(sub, value, path, errs)
+	}
+	if s.Ref != "" {
+		v.validate(v.resolve(s), value, path, errs)
+		return
+	}
+
+	switch s.Type {
+	case "":
+		// Любое значение
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "integer":
+		n, ok := toInteger(value)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		if n == nil || !fitsFormat(n, s.Format) {
+			fail("is out of range")
+			return
+		}
+		validateRange(s, new(big.Float).SetInt(n), fail)
+	case "number":
+		f, ok := toFloat(value)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		validateRange(s, big.NewFloat(f), fail)
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		v.validateString(s, str, fail)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			fail("must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fail("must contain at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		v.validateObject(s, obj, path, errs)
+	}
+}
+
+func validateRange(s *Schema, n *big.Float, fail func(string, ...interface{})) {
+	if s.Minimum != nil {
+		if n.Cmp(big.NewFloat(*s.Minimum)) < 0 {
+			fail("must be greater than or equal to %s", formatBound(*s.Minimum))
+		}
+	}
+	if s.Maximum != nil {
+		if n.Cmp(big.NewFloat(*s.Maximum)) > 0 {
+			fail("must be less than or equal to %s", formatBound(*s.Maximum))
+		}
+	}
+}
+
+func (v *Validator) validateString(s *Schema, str string, fail func(string, ...interface{})) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		if *s.MinLength == 1 {
+			fail("must not be empty")
+		} else {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		fail("must be at most %d characters", *s.MaxLength)
+	}
+	if s.Pattern != "" && !v.pattern(s.Pattern).MatchString(str) {
+		fail("must match pattern %s", s.Pattern)
+	}
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if str == allowed {
+				return
+			}
+		}
+		fail("must be one of: %s", strings.Join(s.Enum, ", "))
+	}
+}
+
+func (v *Validator) validateObject(s *Schema, obj map[string]interface{}, path string, errs *[]FieldError) {
+	prefix := path + "."
+	if path == "" {
+		prefix = ""
+	}
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, FieldError{Path: prefix + name, Reason: "is required"})
+		}
+	}
+
+	// Порядок ошибок не должен зависеть от порядка обхода map
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := s.Properties[name]; ok {
+			v.validate(prop, obj[name], prefix+name, errs)
+		} else if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, obj[name], prefix+name, errs)
+		}
+	}
+}
+
+// pattern компилирует шаблоны из документа. Шаблоны задаются в коде, поэтому
+// ошибка компиляции - ошибка программиста.
+func (v *Validator) pattern(expr string) *regexp.Regexp {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	re, ok := v.patterns[expr]
+	if !ok {
+		re = regexp.MustCompile(expr)
+		v.patterns[expr] = re
+	}
+	return re
+}
+
+func numberString(value interface{}) (string, bool) {
+	switch n := value.(type) {
+	case json.Number:
+		return string(n), true
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	str, ok := numberString(value)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	return f, err == nil
+}
+
+// toInteger возвращает nil и true для целого числа, которое заведомо не
+// помещается в int64. Экспоненциальная запись разбирается через float64,
+// чтобы 1e1000000 не превращалось в число из миллиона цифр.
+func toInteger(value interface{}) (*big.Int, bool) {
+	str, ok := numberString(value)
+	if !ok {
+		return nil, false
+	}
+
+	if strings.ContainsAny(str, ".eE") {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			// Переполнение float64 - целое, но слишком большое
+			return nil, math.IsInf(f, 0)
+		}
+		if f != math.Trunc(f) {
+			return nil, false
+		}
+		if math.Abs(f) > math.MaxInt64 {
+			return nil, true
+		}
+		n, _ := big.NewFloat(f).Int(nil)
+		return n, true
+	}
+
+	if len(strings.TrimLeft(str, "+-0")) > maxIntegerDigits {
+		return nil, true
+	}
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(str, "+"), 10)
+	return n, ok
+}
+
+// Число знаков, больше которого целое точно не помещается в int64
+const maxIntegerDigits = 19
+
+var (
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	numberPattern  = regexp.MustCompile(`^[+-]?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+)
+
+var (
+	minInt32 = big.NewInt(math.MinInt32)
+	maxInt32 = big.NewInt(math.MaxInt32)
+	minInt64 = big.NewInt(math.MinInt64)
+	maxInt64 = big.NewInt(math.MaxInt64)
+)
+
+func fitsFormat(n *big.Int, format string) bool {
+	switch format {
+	case "int32":
+		return n.Cmp(minInt32) >= 0 && n.Cmp(maxInt32) <= 0
+	case "int64":
+		return n.Cmp(minInt64) >= 0 && n.Cmp(maxInt64) <= 0
+	}
+	return true
+}
+
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
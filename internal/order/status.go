@@ -0,0 +1,77 @@
+package order
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	StatusCreated   = "created"
+	StatusConfirmed = "confirmed"
+	StatusCooking   = "cooking"
+	StatusReady     = "ready"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+// transitions - допустимые переходы между статусами заказа.
+// delivered и cancelled - конечные состояния.
+var transitions = map[string][]string{
+	StatusCreated:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCooking, StatusCancelled},
+	StatusCooking:   {StatusReady},
+	StatusReady:     {StatusDelivered},
+	StatusDelivered: {},
+	StatusCancelled: {},
+}
+
+// Statuses возвращает все статусы заказа по алфавиту.
+func Statuses() []string {
+	statuses := make([]string, 0, len(transitions))
+	for status := range transitions {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+func IsKnownStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// IsFinalStatus сообщает, что из статуса нет переходов.
+func IsFinalStatus(status string) bool {
+	next, ok := transitions[status]
+	return ok && len(next) == 0
+}
+
+// NormalizeStatus приводит статус от OrderService к модели BFF. Заказы,
+// созданные до появления статусной модели, приходят без статуса или с "new".
+func NormalizeStatus(status string) string {
+	if status == "" || status == "new" {
+		return StatusCreated
+	}
+	return status
+}
+
+// CheckTransition проверяет переход from -> to. Переход в тот же статус
+// допустим и ничего не меняет.
+func CheckTransition(from, to string) error {
+	from = NormalizeStatus(from)
+	if !IsKnownStatus(from) {
+		return fmt.Errorf("order has unrecognized status %q", from)
+	}
+	if !IsKnownStatus(to) {
+		return fmt.Errorf("unknown order status %q", to)
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change order status from %q to %q", from, to)
+}
@@ -0,0 +1,173 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Options struct {
+	// Currency используется для блюд, у которых валюта не указана
+	Currency string
+	// TaxRate - ставка налога в процентах по умолчанию
+	TaxRate string
+	// CategoryTaxRates переопределяет ставку для категорий блюд
+	CategoryTaxRates map[int32]string
+}
+
+// Calculator считает стоимость заказа. Налог начисляется сверху на сумму
+// позиций и округляется один раз на каждую ставку, а не на каждую позицию.
+type Calculator struct {
+	currency      string
+	defaultRate   Rate
+	categoryRates map[int32]Rate
+}
+
+func NewCalculator(opts Options) (*Calculator, error) {
+	c := &Calculator{
+		currency:      strings.ToUpper(opts.Currency),
+		categoryRates: make(map[int32]Rate, len(opts.CategoryTaxRates)),
+	}
+
+	var err error
+	if c.defaultRate, err = ParseRate(opts.TaxRate); err != nil {
+		return nil, fmt.Errorf("tax rate: %w", err)
+	}
+	for category, raw := range opts.CategoryTaxRates {
+		rate, err := ParseRate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("tax rate for category %d: %w", category, err)
+		}
+		c.categoryRates[category] = rate
+	}
+	return c, nil
+}
+
+// Item - позиция для расчёта. Priced = false, если цену блюда узнать не
+// удалось: такая позиция не входит в суммы и попадает в Bill.Unpriced.
+type Item struct {
+	DishID     int64
+	Quantity   int32
+	Priced     bool
+	UnitPrice  int64
+	Currency   string
+	CategoryID int32
+}
+
+type Line struct {
+	DishID    int64
+	Quantity  int32
+	UnitPrice Money
+	Total     Money
+	TaxRate   Rate
+}
+
+type TaxLine struct {
+	Rate    Rate
+	Taxable Money
+	Tax     Money
+}
+
+type Bill struct {
+	Currency string
+	Lines    []Line
+	Subtotal Money
+	Taxes    []TaxLine
+	Tax      Money
+	Total    Money
+	// Unpriced - id блюд без цены, суммы посчитаны без них
+	Unpriced []int64
+}
+
+// Complete сообщает, что в суммы вошли все позиции заказа.
+func (b *Bill) Complete() bool {
+	return len(b.Unpriced) == 0
+}
+
+// Currency возвращает валюту по умолчанию для цен без указанной валюты.
+func (c *Calculator) Currency() string {
+	return c.currency
+}
+
+func (c *Calculator) Price(items []Item) (*Bill, error) {
+	bill := &Bill{Currency: c.currency, Unpriced: []int64{}}
+
+	currency := ""
+	taxable := make(map[Rate]int64)
+	for _, item := range items {
+		if !item.Priced || item.UnitPrice < 0 {
+			bill.Unpriced = append(bill.Unpriced, item.DishID)
+			continue
+		}
+
+		itemCurrency := strings.ToUpper(item.Currency)
+		if itemCurrency == "" {
+			itemCurrency = c.currency
+		}
+		if currency != "" && itemCurrency != currency {
+			return nil, ErrMixedCurrencies
+		}
+		currency = itemCurrency
+
+		total, err := mul(item.UnitPrice, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		rate := c.rateFor(item.CategoryID)
+		if taxable[rate], err = add(taxable[rate], total); err != nil {
+			return nil, err
+		}
+		if bill.Subtotal.Minor, err = add(bill.Subtotal.Minor, total); err != nil {
+			return nil, err
+		}
+
+		bill.Lines = append(bill.Lines, Line{
+			DishID:    item.DishID,
+			Quantity:  item.Quantity,
+			UnitPrice: Money{Minor: item.UnitPrice, Currency: itemCurrency},
+			Total:     Money{Minor: total, Currency: itemCurrency},
+			TaxRate:   rate,
+		})
+	}
+	if currency != "" {
+		bill.Currency = currency
+	}
+
+	// Порядок ставок фиксирован, чтобы ответ не менялся от запроса к запросу
+	rates := make([]Rate, 0, len(taxable))
+	for rate := range taxable {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].micro > rates[j].micro })
+
+	for _, rate := range rates {
+		tax, err := rate.apply(taxable[rate])
+		if err != nil {
+			return nil, err
+		}
+		if bill.Tax.Minor, err = add(bill.Tax.Minor, tax); err != nil {
+			return nil, err
+		}
+		bill.Taxes = append(bill.Taxes, TaxLine{
+			Rate:    rate,
+			Taxable: Money{Minor: taxable[rate], Currency: bill.Currency},
+			Tax:     Money{Minor: tax, Currency: bill.Currency},
+		})
+	}
+
+	total, err := add(bill.Subtotal.Minor, bill.Tax.Minor)
+	if err != nil {
+		return nil, err
+	}
+	bill.Subtotal.Currency = bill.Currency
+	bill.Tax.Currency = bill.Currency
+	bill.Total = Money{Minor: total, Currency: bill.Currency}
+	return bill, nil
+}
+
+func (c *Calculator) rateFor(categoryID int32) Rate {
+	if rate, ok := c.categoryRates[categoryID]; ok {
+		return rate
+	}
+	return c.defaultRate
+}
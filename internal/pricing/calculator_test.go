@@ -0,0 +1,199 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func newTestCalculator(t *testing.T) *Calculator {
+	t.Helper()
+
+	c, err := NewCalculator(Options{
+		Currency:         "rub",
+		TaxRate:          "20",
+		CategoryTaxRates: map[int32]string{3: "10", 4: "0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCalculatorPrice(t *testing.T) {
+	type tax struct {
+		rate    string
+		taxable int64
+		tax     int64
+	}
+	tests := []struct {
+		name     string
+		items    []Item
+		currency string
+		subtotal int64
+		taxes    []tax
+		total    int64
+		unpriced []int64
+	}{
+		{
+			name:     "empty order",
+			currency: "RUB",
+		},
+		{
+			name:     "default currency and rate",
+			items:    []Item{{DishID: 1, Quantity: 2, Priced: true, UnitPrice: 35000}},
+			currency: "RUB",
+			subtotal: 70000,
+			taxes:    []tax{{"20", 70000, 14000}},
+			total:    84000,
+		},
+		{
+			name: "rate per category",
+			items: []Item{
+				{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 1000, Currency: "RUB"},
+				{DishID: 2, Quantity: 3, Priced: true, UnitPrice: 500, CategoryID: 3},
+				{DishID: 3, Quantity: 1, Priced: true, UnitPrice: 700, CategoryID: 4},
+			},
+			currency: "RUB",
+			subtotal: 3200,
+			taxes:    []tax{{"20", 1000, 200}, {"10", 1500, 150}, {"0", 700, 0}},
+			total:    3550,
+		},
+		{
+			// 20% от 0.03 = 0.006, округляется вверх до 0.01
+			name:     "half up",
+			items:    []Item{{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 3}},
+			currency: "RUB",
+			subtotal: 3,
+			taxes:    []tax{{"20", 3, 1}},
+			total:    4,
+		},
+		{
+			// 20% от 0.02 = 0.004, округляется вниз
+			name:     "below half",
+			items:    []Item{{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 2}},
+			currency: "RUB",
+			subtotal: 2,
+			taxes:    []tax{{"20", 2, 0}},
+			total:    2,
+		},
+		{
+			// Налог считается с суммы по ставке, а не с каждой позиции:
+			// 10% от 0.05 + 0.05 = 0.01, а не 0.01 + 0.01
+			name: "rounded once per rate",
+			items: []Item{
+				{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 5, CategoryID: 3},
+				{DishID: 2, Quantity: 1, Priced: true, UnitPrice: 5, CategoryID: 3},
+			},
+			currency: "RUB",
+			subtotal: 10,
+			taxes:    []tax{{"10", 10, 1}},
+			total:    11,
+		},
+		{
+			name: "unpriced dishes",
+			items: []Item{
+				{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 1000},
+				{DishID: 4, Quantity: 2},
+				{DishID: 5, Quantity: 1, Priced: true, UnitPrice: -1},
+			},
+			currency: "RUB",
+			subtotal: 1000,
+			taxes:    []tax{{"20", 1000, 200}},
+			total:    1200,
+			unpriced: []int64{4, 5},
+		},
+		{
+			name:     "dish currency",
+			items:    []Item{{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 1000, Currency: "usd"}},
+			currency: "USD",
+			subtotal: 1000,
+			taxes:    []tax{{"20", 1000, 200}},
+			total:    1200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill, err := newTestCalculator(t).Price(tt.items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bill.Currency != tt.currency {
+				t.Errorf("currency = %q, want %q", bill.Currency, tt.currency)
+			}
+			if bill.Subtotal.Minor != tt.subtotal || bill.Total.Minor != tt.total {
+				t.Errorf("subtotal, total = %d, %d, want %d, %d", bill.Subtotal.Minor, bill.Total.Minor, tt.subtotal, tt.total)
+			}
+			if bill.Total.Currency != tt.currency {
+				t.Errorf("total currency = %q, want %q", bill.Total.Currency, tt.currency)
+			}
+			if len(bill.Taxes) != len(tt.taxes) {
+				t.Fatalf("taxes = %+v, want %+v", bill.Taxes, tt.taxes)
+			}
+			for i, want := range tt.taxes {
+				got := bill.Taxes[i]
+				if got.Rate.String() != want.rate || got.Taxable.Minor != want.taxable || got.Tax.Minor != want.tax {
+					t.Errorf("tax %d = %s%% of %d is %d, want %s%% of %d is %d",
+						i, got.Rate, got.Taxable.Minor, got.Tax.Minor, want.rate, want.taxable, want.tax)
+				}
+			}
+			if len(bill.Unpriced) != len(tt.unpriced) {
+				t.Fatalf("unpriced = %v, want %v", bill.Unpriced, tt.unpriced)
+			}
+			for i := range tt.unpriced {
+				if bill.Unpriced[i] != tt.unpriced[i] {
+					t.Errorf("unpriced = %v, want %v", bill.Unpriced, tt.unpriced)
+				}
+			}
+			if bill.Complete() != (len(tt.unpriced) == 0) {
+				t.Errorf("Complete() = %v", bill.Complete())
+			}
+		})
+	}
+}
+
+func TestCalculatorPriceErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []Item
+		want  error
+	}{
+		{
+			name: "mixed currencies",
+			items: []Item{
+				{DishID: 1, Quantity: 1, Priced: true, UnitPrice: 100, Currency: "RUB"},
+				{DishID: 2, Quantity: 1, Priced: true, UnitPrice: 100, Currency: "USD"},
+			},
+			want: ErrMixedCurrencies,
+		},
+		{
+			name:  "line overflow",
+			items: []Item{{DishID: 1, Quantity: 2, Priced: true, UnitPrice: math.MaxInt64}},
+			want:  ErrOverflow,
+		},
+		{
+			name: "subtotal overflow",
+			items: []Item{
+				{DishID: 1, Quantity: 1, Priced: true, UnitPrice: math.MaxInt64},
+				{DishID: 2, Quantity: 1, Priced: true, UnitPrice: 1, CategoryID: 4},
+			},
+			want: ErrOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTestCalculator(t).Price(tt.items); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCalculatorInvalidRate(t *testing.T) {
+	if _, err := NewCalculator(Options{Currency: "RUB", TaxRate: "150"}); err == nil {
+		t.Error("NewCalculator with a 150% rate: want error")
+	}
+	if _, err := NewCalculator(Options{Currency: "RUB", TaxRate: "20", CategoryTaxRates: map[int32]string{1: "x"}}); err == nil {
+		t.Error("NewCalculator with an invalid category rate: want error")
+	}
+}
@@ -0,0 +1,142 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMixedCurrencies = errors.New("order contains dishes priced in different currencies")
+	ErrOverflow        = errors.New("amount is out of range")
+)
+
+// Число знаков после запятой для валют, у которых оно отличается от двух
+var currencyExponents = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// Money - сумма в минимальных единицах валюты. Дробные значения не
+// используются, чтобы суммы считались точно.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// Exponent возвращает число знаков после запятой для валюты.
+func Exponent(currency string) int {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// String форматирует сумму в основных единицах валюты, например "12.50".
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	digits := strconv.FormatInt(m.Minor, 10)
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func add(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+func mul(a int64, n int32) (int64, error) {
+	result := new(big.Int).Mul(big.NewInt(a), big.NewInt(int64(n)))
+	if !result.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return result.Int64(), nil
+}
+
+// Rate - процентная ставка, хранится в миллионных долях процента.
+type Rate struct {
+	micro int64
+}
+
+const (
+	rateScale    = 1_000_000
+	rateDecimals = 6
+)
+
+// ParseRate разбирает ставку в процентах, например "20" или "8.875".
+func ParseRate(percent string) (Rate, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(percent), "%")
+	whole, frac, _ := strings.Cut(s, ".")
+	if !isDigits(whole) || len(whole) > 3 || len(frac) > rateDecimals || (frac != "" && !isDigits(frac)) {
+		return Rate{}, fmt.Errorf("invalid rate %q", percent)
+	}
+
+	w, _ := strconv.ParseInt(whole, 10, 64)
+	var f int64
+	if frac != "" {
+		f, _ = strconv.ParseInt(frac+strings.Repeat("0", rateDecimals-len(frac)), 10, 64)
+	}
+
+	r := Rate{micro: w*rateScale + f}
+	if r.micro > 100*rateScale {
+		return Rate{}, fmt.Errorf("rate %q exceeds 100%%", percent)
+	}
+	return r, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String возвращает ставку в процентах без лишних нулей, например "8.875".
+func (r Rate) String() string {
+	s := strconv.FormatInt(r.micro/rateScale, 10)
+	if frac := r.micro % rateScale; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%06d", frac), "0")
+	}
+	return s
+}
+
+// apply возвращает налог с суммы amount, округлённый до минимальной
+// единицы по правилу половина вверх.
+func (r Rate) apply(amount int64) (int64, error) {
+	// amount * micro / (100 * rateScale) с округлением
+	num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(r.micro))
+	den := big.NewInt(100 * rateScale)
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).CmpAbs(den) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	if !quo.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quo.Int64(), nil
+}
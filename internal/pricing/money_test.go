@@ -0,0 +1,62 @@
+package pricing
+
+import "testing"
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Minor: 0, Currency: "RUB"}, "0.00"},
+		{Money{Minor: 5, Currency: "RUB"}, "0.05"},
+		{Money{Minor: 1250, Currency: "RUB"}, "12.50"},
+		{Money{Minor: -1250, Currency: "RUB"}, "-12.50"},
+		{Money{Minor: -5, Currency: "USD"}, "-0.05"},
+		{Money{Minor: 1250, Currency: ""}, "12.50"},
+		{Money{Minor: 1250, Currency: "JPY"}, "1250"},
+		{Money{Minor: -1250, Currency: "jpy"}, "-1250"},
+		{Money{Minor: 1250, Currency: "KWD"}, "1.250"},
+		{Money{Minor: 7, Currency: "KWD"}, "0.007"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%d %s: String() = %q, want %q", tt.money.Minor, tt.money.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		percent string
+		want    string
+		wantErr bool
+	}{
+		{percent: "20", want: "20"},
+		{percent: "8.875", want: "8.875"},
+		{percent: "10.50", want: "10.5"},
+		{percent: " 8% ", want: "8"},
+		{percent: "0", want: "0"},
+		{percent: "100", want: "100"},
+		{percent: "100.000001", wantErr: true},
+		{percent: "-5", wantErr: true},
+		{percent: "1.1234567", wantErr: true},
+		{percent: "abc", wantErr: true},
+		{percent: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		rate, err := ParseRate(tt.percent)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRate(%q) = %v, want error", tt.percent, rate)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRate(%q): %v", tt.percent, err)
+			continue
+		}
+		if got := rate.String(); got != tt.want {
+			t.Errorf("ParseRate(%q) = %s, want %s", tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit - параметры корзины токенов: Rate запросов в секунду в среднем и
+// до Burst запросов подряд.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result - решение по одному запросу.
+type Result struct {
+	Allowed bool
+	// Remaining - сколько запросов ещё можно сделать без ожидания
+	Remaining int
+	// RetryAfter - через сколько появится следующий токен, если запрос
+	// отклонён
+	RetryAfter time.Duration
+	// Reset - через сколько корзина заполнится полностью
+	Reset time.Duration
+}
+
+// Store хранит корзины токенов. Реализация в памяти считает лимиты для
+// одного экземпляра сервиса, для общих лимитов нескольких экземпляров нужен
+// общий бэкенд.
+type Store interface {
+	// Take забирает токен из корзины key.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full - момент, когда корзина заполнится и её можно удалить
+	full time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	burst := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	var res Result
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / limit.Rate)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep удаляет заполненные корзины не чаще раза в минуту: новая корзина
+// ничем от них не отличается.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,69 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type DishService struct {
+	pbDishes.UnimplementedDishServiceServer
+	faultRecorder
+
+	mu     sync.Mutex
+	dishes map[int32]*pbDishes.Dish
+}
+
+func NewDishService(dishes ...*pbDishes.Dish) *DishService {
+	s := &DishService{}
+	s.SetDishes(dishes...)
+	return s
+}
+
+// SetDishes заменяет меню.
+func (s *DishService) SetDishes(dishes ...*pbDishes.Dish) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dishes = make(map[int32]*pbDishes.Dish, len(dishes))
+	for _, dish := range dishes {
+		s.dishes[dish.Id] = proto.Clone(dish).(*pbDishes.Dish)
+	}
+}
+
+// RemoveDish убирает блюдо из меню, например чтобы проверить заказы с
+// удалёнными блюдами.
+func (s *DishService) RemoveDish(id int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dishes, id)
+}
+
+// GetDishes возвращает всё меню при id = 0, иначе одно блюдо или пустой
+// список, если блюда нет.
+func (s *DishService) GetDishes(ctx context.Context, req *pbDishes.DishRequest) (*pbDishes.DishesResponse, error) {
+	if err := s.intercept(ctx, "GetDishes", req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &pbDishes.DishesResponse{}
+	if req.Id != 0 {
+		if dish, ok := s.dishes[req.Id]; ok {
+			resp.Dishes = append(resp.Dishes, proto.Clone(dish).(*pbDishes.Dish))
+		}
+		return resp, nil
+	}
+
+	for _, dish := range s.dishes {
+		resp.Dishes = append(resp.Dishes, proto.Clone(dish).(*pbDishes.Dish))
+	}
+	sort.Slice(resp.Dishes, func(i, j int) bool { return resp.Dishes[i].Id < resp.Dishes[j].Id })
+	return resp, nil
+}
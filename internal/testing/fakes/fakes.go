@@ -0,0 +1,130 @@
+// Package fakes содержит DishService и OrderService в памяти для тестов и
+// локального запуска BFF без настоящих бэкендов. Сервисы поддерживают
+// начальные данные, внедрение ошибок и задержек и запись всех вызовов.
+package fakes
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fault - ошибка или задержка, которую сервис вносит в вызовы метода.
+type Fault struct {
+	// Delay выдерживается до ответа или до отмены вызова
+	Delay time.Duration
+	// Code - код ошибки; codes.OK оставляет только задержку
+	Code    codes.Code
+	Message string
+	// Times - число затронутых вызовов, 0 - все до ClearFaults
+	Times int
+	// Probability - доля вызовов от 0 до 1, которые завершаются ошибкой
+	// Code, 0 - все. Задержка действует на все вызовы
+	Probability float64
+}
+
+// AllMethods вместо имени метода в Fail вносит ошибку во все методы, для
+// которых нет своей.
+const AllMethods = "*"
+
+// Call - записанный вызов метода.
+type Call struct {
+	// Method - короткое имя метода, например "GetDishes"
+	Method   string
+	Request  proto.Message
+	Metadata metadata.MD
+}
+
+// faultRecorder записывает вызовы и применяет к ним ошибки. Встраивается
+// в оба сервиса.
+type faultRecorder struct {
+	mu     sync.Mutex
+	calls  []Call
+	faults map[string]*Fault
+}
+
+// Fail вносит ошибку в вызовы метода method, заменяя предыдущую.
+func (r *faultRecorder) Fail(method string, fault Fault) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.faults == nil {
+		r.faults = make(map[string]*Fault)
+	}
+	r.faults[method] = &fault
+}
+
+func (r *faultRecorder) ClearFaults() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.faults = nil
+}
+
+// Calls возвращает записанные вызовы метода method или все вызовы, если
+// method пустой.
+func (r *faultRecorder) Calls(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, call := range r.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (r *faultRecorder) ResetCalls() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// intercept записывает вызов и возвращает внедрённую ошибку, если она есть.
+func (r *faultRecorder) intercept(ctx context.Context, method string, req proto.Message) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{
+		Method:   method,
+		Request:  proto.Clone(req),
+		Metadata: md.Copy(),
+	})
+	var fault Fault
+	key := method
+	f, ok := r.faults[key]
+	if !ok {
+		key = AllMethods
+		f, ok = r.faults[key]
+	}
+	if ok {
+		fault = *f
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				delete(r.faults, key)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+	if fault.Code != codes.OK && (fault.Probability <= 0 || rand.Float64() < fault.Probability) {
+		return status.Error(fault.Code, fault.Message)
+	}
+	return nil
+}
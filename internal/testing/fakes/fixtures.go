@@ -0,0 +1,52 @@
+package fakes
+
+import (
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+)
+
+// Dishes возвращает небольшое меню для тестов: блюда разных типов и
+// категорий, одно без указанной валюты и одно бесплатное.
+func Dishes() []*pbDishes.Dish {
+	return []*pbDishes.Dish{
+		{
+			Id:       1,
+			Name:     "Борщ",
+			Type:     &pbDishes.Type{Id: 1, TypeDish: "Суп"},
+			Category: &pbDishes.Category{Id: 1, CategoryDish: "Обед"},
+			NutFact:  &pbDishes.NutritionFact{Id: 1, Calories: 250, Proteins: 12, Fats: 10, Carbohydrates: 28},
+			Tag:      &pbDishes.Tag{Id: 1, TagDish: "Хит"},
+			Recipe:   "Свёкла, капуста, говядина",
+			Price:    35000,
+			Currency: "RUB",
+		},
+		{
+			Id:       2,
+			Name:     "Пельмени",
+			Type:     &pbDishes.Type{Id: 2, TypeDish: "Горячее"},
+			Category: &pbDishes.Category{Id: 1, CategoryDish: "Обед"},
+			NutFact:  &pbDishes.NutritionFact{Id: 2, Calories: 480, Proteins: 22, Fats: 24, Carbohydrates: 40},
+			Tag:      &pbDishes.Tag{Id: 2, TagDish: "Сытно"},
+			Recipe:   "Тесто, свинина, говядина",
+			Price:    42000,
+			Currency: "RUB",
+		},
+		{
+			Id:       3,
+			Name:     "Морс",
+			Type:     &pbDishes.Type{Id: 3, TypeDish: "Напиток"},
+			Category: &pbDishes.Category{Id: 3, CategoryDish: "Напитки"},
+			NutFact:  &pbDishes.NutritionFact{Id: 3, Calories: 90, Carbohydrates: 22},
+			Tag:      &pbDishes.Tag{Id: 3, TagDish: "Без сахара"},
+			Recipe:   "Клюква, вода",
+			Price:    12000,
+		},
+		{
+			Id:       4,
+			Name:     "Сезонное блюдо",
+			Type:     &pbDishes.Type{Id: 2, TypeDish: "Горячее"},
+			Category: &pbDishes.Category{Id: 2, CategoryDish: "Ужин"},
+			NutFact:  &pbDishes.NutritionFact{Id: 4, Calories: 600, Proteins: 30, Fats: 35, Carbohydrates: 20},
+			Tag:      &pbDishes.Tag{Id: 4, TagDish: "Новинка"},
+		},
+	}
+}
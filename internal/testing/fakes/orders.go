@@ -0,0 +1,342 @@
+package fakes
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/order"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultListPageSize = 20
+	// Ёмкость очереди событий одного потока WatchOrder. При переполнении
+	// поток закрывается с ошибкой, как у перегруженного сервиса
+	watchQueueSize = 64
+)
+
+type storedOrder struct {
+	order    *pbOrders.OrderResponse
+	sequence uint64
+}
+
+type OrderService struct {
+	pbOrders.UnimplementedOrderServiceServer
+	faultRecorder
+
+	mu       sync.Mutex
+	nextID   uint64
+	orders   map[uint64]*storedOrder
+	watchers map[uint64]map[chan *pbOrders.OrderEvent]struct{}
+	onChange func()
+}
+
+func NewOrderService() *OrderService {
+	return &OrderService{
+		orders:   make(map[uint64]*storedOrder),
+		watchers: make(map[uint64]map[chan *pbOrders.OrderEvent]struct{}),
+	}
+}
+
+// Seed добавляет заказы как есть. Заказам без id назначается следующий
+// свободный id. Возвращает сохранённые заказы.
+func (s *OrderService) Seed(orders ...*pbOrders.OrderResponse) []*pbOrders.OrderResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seeded := make([]*pbOrders.OrderResponse, 0, len(orders))
+	for _, o := range orders {
+		o = proto.Clone(o).(*pbOrders.OrderResponse)
+		if o.Id == 0 {
+			o.Id = s.nextID + 1
+		}
+		if o.Id > s.nextID {
+			s.nextID = o.Id
+		}
+		s.orders[o.Id] = &storedOrder{order: o, sequence: 1}
+		seeded = append(seeded, proto.Clone(o).(*pbOrders.OrderResponse))
+	}
+	return seeded
+}
+
+// OnChange задаёт функцию, которая вызывается после каждого изменения
+// заказов, кроме Seed, например для сохранения их на диск.
+func (s *OrderService) OnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Orders возвращает все заказы по возрастанию id.
+func (s *OrderService) Orders() []*pbOrders.OrderResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*pbOrders.OrderResponse, 0, len(s.orders))
+	for _, stored := range s.orders {
+		orders = append(orders, proto.Clone(stored.order).(*pbOrders.OrderResponse))
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Id < orders[j].Id })
+	return orders
+}
+
+// changed вызывает обработчик OnChange. Вызывается без s.mu.
+func (s *OrderService) changed() {
+	s.mu.Lock()
+	fn := s.onChange
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// Order возвращает текущее состояние заказа.
+func (s *OrderService) Order(id uint64) (*pbOrders.OrderResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.orders[id]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(stored.order).(*pbOrders.OrderResponse), true
+}
+
+// SetStatus меняет статус заказа в обход API, как если бы его изменила
+// кухня, и уведомляет подписчиков WatchOrder.
+func (s *OrderService) SetStatus(id uint64, orderStatus string) bool {
+	s.mu.Lock()
+	stored, ok := s.orders[id]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	stored.order.Status = orderStatus
+	s.publish(stored)
+	s.mu.Unlock()
+
+	s.changed()
+	return true
+}
+
+func (s *OrderService) CreateOrder(ctx context.Context, req *pbOrders.CreateOrderRequest) (*pbOrders.OrderResponse, error) {
+	if err := s.intercept(ctx, "CreateOrder", req); err != nil {
+		return nil, err
+	}
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if len(req.Items) == 0 && len(req.OrderItems) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order has no items")
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	o := &pbOrders.OrderResponse{
+		Id:         s.nextID,
+		UserId:     req.UserId,
+		Items:      req.Items,
+		OrderItems: req.OrderItems,
+		Status:     order.StatusCreated,
+	}
+	s.orders[o.Id] = &storedOrder{order: proto.Clone(o).(*pbOrders.OrderResponse), sequence: 1}
+	s.mu.Unlock()
+
+	s.changed()
+	return o, nil
+}
+
+func (s *OrderService) GetOrder(ctx context.Context, req *pbOrders.GetOrderRequest) (*pbOrders.OrderResponse, error) {
+	if err := s.intercept(ctx, "GetOrder", req); err != nil {
+		return nil, err
+	}
+
+	o, ok := s.Order(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "order %d not found", req.Id)
+	}
+	return o, nil
+}
+
+// UpdateOrder меняет только заданные поля.
+func (s *OrderService) UpdateOrder(ctx context.Context, req *pbOrders.UpdateOrderRequest) (*pbOrders.OrderResponse, error) {
+	if err := s.intercept(ctx, "UpdateOrder", req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	stored, ok := s.orders[req.Id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.NotFound, "order %d not found", req.Id)
+	}
+	o := stored.order
+	if req.UserId != 0 {
+		o.UserId = req.UserId
+	}
+	if len(req.Items) > 0 || len(req.OrderItems) > 0 {
+		o.Items = req.Items
+		o.OrderItems = req.OrderItems
+	}
+	if req.Status != "" {
+		o.Status = req.Status
+	}
+	s.publish(stored)
+	updated := proto.Clone(o).(*pbOrders.OrderResponse)
+	s.mu.Unlock()
+
+	s.changed()
+	return updated, nil
+}
+
+func (s *OrderService) DeleteOrder(ctx context.Context, req *pbOrders.DeleteOrderRequest) (*pbOrders.DeleteOrderResponse, error) {
+	if err := s.intercept(ctx, "DeleteOrder", req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	_, ok := s.orders[req.Id]
+	delete(s.orders, req.Id)
+	s.mu.Unlock()
+
+	if ok {
+		s.changed()
+	}
+	return &pbOrders.DeleteOrderResponse{Deleted: ok}, nil
+}
+
+// ListOrders отдаёт заказы по возрастанию id. page_token - смещение в
+// отфильтрованном списке.
+func (s *OrderService) ListOrders(ctx context.Context, req *pbOrders.ListOrdersRequest) (*pbOrders.ListOrdersResponse, error) {
+	if err := s.intercept(ctx, "ListOrders", req); err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if req.PageToken != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.PageToken); err != nil || offset < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+
+	s.mu.Lock()
+	var matched []*pbOrders.OrderResponse
+	for _, stored := range s.orders {
+		o := stored.order
+		if req.UserId != 0 && o.UserId != req.UserId {
+			continue
+		}
+		if req.Status != "" && order.NormalizeStatus(o.Status) != req.Status {
+			continue
+		}
+		matched = append(matched, proto.Clone(o).(*pbOrders.OrderResponse))
+	}
+	s.mu.Unlock()
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Id < matched[j].Id })
+
+	resp := &pbOrders.ListOrdersResponse{}
+	if offset >= len(matched) {
+		return resp, nil
+	}
+	end := offset + pageSize
+	if end < len(matched) {
+		resp.NextPageToken = strconv.Itoa(end)
+	} else {
+		end = len(matched)
+	}
+	resp.Orders = matched[offset:end]
+	return resp, nil
+}
+
+// WatchOrder сразу отправляет текущее состояние заказа, если оно новее
+// after_sequence, затем каждое изменение. Поток закрывается после перехода
+// заказа в конечный статус.
+func (s *OrderService) WatchOrder(req *pbOrders.WatchOrderRequest, stream pbOrders.OrderService_WatchOrderServer) error {
+	ctx := stream.Context()
+	if err := s.intercept(ctx, "WatchOrder", req); err != nil {
+		return err
+	}
+
+	events := make(chan *pbOrders.OrderEvent, watchQueueSize)
+	s.mu.Lock()
+	stored, ok := s.orders[req.Id]
+	if !ok {
+		s.mu.Unlock()
+		return status.Errorf(codes.NotFound, "order %d not found", req.Id)
+	}
+	if stored.sequence > req.AfterSequence {
+		events <- orderEvent(stored)
+	}
+	if s.watchers[req.Id] == nil {
+		s.watchers[req.Id] = make(map[chan *pbOrders.OrderEvent]struct{})
+	}
+	s.watchers[req.Id][events] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers[req.Id], events)
+		if len(s.watchers[req.Id]) == 0 {
+			delete(s.watchers, req.Id)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher is too slow")
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+			if order.IsFinalStatus(order.NormalizeStatus(ev.Order.Status)) {
+				return nil
+			}
+		}
+	}
+}
+
+// Watchers возвращает число открытых потоков WatchOrder заказа.
+func (s *OrderService) Watchers(id uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.watchers[id])
+}
+
+// publish вызывается под s.mu после изменения заказа.
+func (s *OrderService) publish(stored *storedOrder) {
+	stored.sequence++
+	ev := orderEvent(stored)
+	for events := range s.watchers[stored.order.Id] {
+		select {
+		case events <- ev:
+		default:
+			close(events)
+			delete(s.watchers[stored.order.Id], events)
+		}
+	}
+}
+
+func orderEvent(stored *storedOrder) *pbOrders.OrderEvent {
+	return &pbOrders.OrderEvent{
+		Sequence:         stored.sequence,
+		Order:            proto.Clone(stored.order).(*pbOrders.OrderResponse),
+		OccurredAtUnixMs: time.Now().UnixMilli(),
+	}
+}
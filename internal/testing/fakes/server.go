@@ -0,0 +1,68 @@
+package fakes
+
+import (
+	"context"
+	"net"
+
+	pbDishes "github.com/anyviewww/bff-service/proto/dishes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1 << 20
+
+// Server обслуживает оба сервиса в памяти через bufconn, без сетевых портов.
+type Server struct {
+	Dishes *DishService
+	Orders *OrderService
+
+	grpc     *grpc.Server
+	listener *bufconn.Listener
+}
+
+// NewServer запускает сервер с меню из Dishes и пустым списком заказов.
+func NewServer() *Server {
+	s := &Server{
+		Dishes:   NewDishService(Dishes()...),
+		Orders:   NewOrderService(),
+		grpc:     grpc.NewServer(),
+		listener: bufconn.Listen(bufSize),
+	}
+	Register(s.grpc, s.Dishes, s.Orders)
+	go s.grpc.Serve(s.listener)
+	return s
+}
+
+// Register регистрирует сервисы и стандартный health check на сервере.
+func Register(srv *grpc.Server, dishes *DishService, orders *OrderService) {
+	pbDishes.RegisterDishServiceServer(srv, dishes)
+	pbOrders.RegisterOrderServiceServer(srv, orders)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+}
+
+// DialOption направляет соединение в сервер вместо сети. Адрес для
+// grpc.Dial может быть любым, например "bufconn".
+func (s *Server) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	})
+}
+
+// Dial открывает незашифрованное соединение с сервером.
+func (s *Server) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		s.DialOption(),
+	}, opts...)
+	return grpc.Dial("bufconn", opts...)
+}
+
+// Close останавливает сервер и разрывает открытые соединения.
+func (s *Server) Close() {
+	s.grpc.Stop()
+}
@@ -0,0 +1,58 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// Client возвращает учётные данные gRPC-клиента. Конфигурация TLS
+// собирается из текущих сертификатов r при каждом новом подключении,
+// поэтому переподключения после перезагрузки используют новые файлы.
+func Client(r *Reloader, serverName string) credentials.TransportCredentials {
+	return &clientCredentials{reloader: r, serverName: serverName}
+}
+
+type clientCredentials struct {
+	reloader   *Reloader
+	serverName string
+}
+
+func (c *clientCredentials) config() *tls.Config {
+	m := c.reloader.material()
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.serverName,
+		RootCAs:    m.pool,
+	}
+	if m.cert != nil {
+		cfg.Certificates = []tls.Certificate{*m.cert}
+	}
+	return cfg
+}
+
+func (c *clientCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(c.config()).ClientHandshake(ctx, authority, conn)
+}
+
+func (c *clientCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("tlsconfig: client credentials cannot be used on the server side")
+}
+
+func (c *clientCredentials) Info() credentials.ProtocolInfo {
+	return credentials.NewTLS(c.config()).Info()
+}
+
+func (c *clientCredentials) Clone() credentials.TransportCredentials {
+	clone := *c
+	return &clone
+}
+
+func (c *clientCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
@@ -0,0 +1,160 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files - пути к сертификату, ключу и CA. Любое поле может быть пустым:
+// без пары сертификат/ключ своя сторона не предъявляет сертификат, без CA
+// используются системные корневые сертификаты (клиент) или клиентские
+// сертификаты не проверяются (сервер).
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+func (f Files) paths() []string {
+	var paths []string
+	for _, p := range []string{f.CertFile, f.KeyFile, f.CAFile} {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+type material struct {
+	cert *tls.Certificate
+	pool *x509.CertPool
+}
+
+// Reloader держит загруженные сертификаты и перечитывает файлы, когда
+// меняется их время модификации или размер. Если новые файлы не удаётся
+// загрузить, продолжают использоваться прежние.
+type Reloader struct {
+	name  string
+	files Files
+
+	mu      sync.RWMutex
+	current material
+	stamp   string
+}
+
+// NewReloader загружает файлы сразу, чтобы ошибки конфигурации
+// обнаруживались при старте.
+func NewReloader(name string, files Files) (*Reloader, error) {
+	r := &Reloader{name: name, files: files}
+
+	m, err := load(files)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	r.current = m
+	r.stamp = r.fileStamp()
+	return r, nil
+}
+
+// Watch проверяет файлы с интервалом interval, пока не отменён ctx.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || len(r.files.paths()) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reloadIfChanged()
+		}
+	}
+}
+
+func (r *Reloader) reloadIfChanged() {
+	stamp := r.fileStamp()
+
+	r.mu.RLock()
+	changed := stamp != r.stamp
+	r.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	// Запоминаем отметку и при неудаче: файлы часто обновляются по одному,
+	// и следующая запись снова изменит отметку
+	m, err := load(r.files)
+	r.mu.Lock()
+	r.stamp = stamp
+	if err == nil {
+		r.current = m
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		slog.Error("failed to reload certificates, keeping previous ones",
+			slog.String("name", r.name), slog.Any("error", err))
+		return
+	}
+	slog.Info("certificates reloaded", slog.String("name", r.name))
+}
+
+func (r *Reloader) fileStamp() string {
+	var b strings.Builder
+	for _, p := range r.files.paths() {
+		info, err := os.Stat(p)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:missing;", p)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", p, info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String()
+}
+
+func (r *Reloader) material() material {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+func load(files Files) (material, error) {
+	var m material
+
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return m, errors.New("cert file and key file must be set together")
+	}
+	if files.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return m, fmt.Errorf("load key pair: %w", err)
+		}
+		m.cert = &cert
+	}
+
+	if files.CAFile != "" {
+		data, err := os.ReadFile(files.CAFile)
+		if err != nil {
+			return m, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return m, fmt.Errorf("no certificates found in CA file %s", files.CAFile)
+		}
+		m.pool = pool
+	}
+
+	return m, nil
+}
@@ -0,0 +1,33 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+// Server возвращает конфигурацию HTTPS-сервера. Сертификаты берутся из
+// r при каждом рукопожатии, поэтому перезагрузка не требует перезапуска
+// listener. Если у r задан CA, клиент обязан предъявить подписанный им
+// сертификат (mTLS).
+func Server(r *Reloader) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			m := r.material()
+			if m.cert == nil {
+				return nil, errors.New("server certificate is not configured")
+			}
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*m.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if m.pool != nil {
+				cfg.ClientCAs = m.pool
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
@@ -0,0 +1,328 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/order"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Число последних событий заказа, которые хранятся для возобновления
+	// подписки по Last-Event-ID
+	replayBufferSize = 32
+	// Ёмкость очереди подписчика сверх буфера повтора. Подписчик, который
+	// не успевает читать, отключается и должен переподключиться
+	subscriberQueueSize = 16
+
+	maxReconnectAttempts = 5
+	reconnectBackoff     = 200 * time.Millisecond
+	maxReconnectBackoff  = 5 * time.Second
+)
+
+var (
+	ErrShuttingDown = errors.New("order watcher is shutting down")
+	ErrLagging      = errors.New("subscriber is too slow and was disconnected")
+)
+
+type Event struct {
+	Sequence   uint64
+	Order      *pbOrders.OrderResponse
+	OccurredAt time.Time
+}
+
+// Watcher раздаёт изменения заказов подписчикам. На каждый заказ открывается
+// один поток WatchOrder к OrderService, сколько бы HTTP-клиентов за ним ни
+// следило. Поток закрывается, когда уходит последний подписчик.
+type Watcher struct {
+	client pbOrders.OrderServiceClient
+
+	mu     sync.Mutex
+	hubs   map[uint64]*hub
+	closed bool
+}
+
+func NewWatcher(client pbOrders.OrderServiceClient) *Watcher {
+	return &Watcher{
+		client: client,
+		hubs:   make(map[uint64]*hub),
+	}
+}
+
+// hub - общий поток одного заказа. Все поля защищены Watcher.mu.
+type hub struct {
+	orderID uint64
+	cancel  context.CancelFunc
+	subs    map[*Subscription]struct{}
+	buffer  []Event
+}
+
+type Subscription struct {
+	watcher *Watcher
+	hub     *hub
+	events  chan Event
+	// lastSequence - последнее событие, которое есть у подписчика. Новый
+	// поток начинается с текущего состояния заказа, и подписчик, пришедший
+	// с Last-Event-ID, не должен получить его повторно
+	lastSequence uint64
+	err          error
+	closed       bool
+}
+
+// Events возвращает канал событий. Канал закрывается, когда подписка
+// завершена, причину можно узнать через Err.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err возвращает nil, если заказ перешёл в конечный статус и событий больше
+// не будет, иначе причину завершения подписки.
+func (s *Subscription) Err() error {
+	s.watcher.mu.Lock()
+	defer s.watcher.mu.Unlock()
+	return s.err
+}
+
+// Close отписывается от заказа. Вызывать можно несколько раз.
+func (s *Subscription) Close() {
+	w := s.watcher
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	w.closeSubscription(s, nil)
+
+	h := s.hub
+	if len(h.subs) == 0 && w.hubs[h.orderID] == h {
+		h.cancel()
+		delete(w.hubs, h.orderID)
+	}
+}
+
+// Subscribe подписывается на изменения заказа. Если lastSequence больше
+// нуля, сначала отправляются пропущенные события из буфера; если буфер их
+// уже не содержит, отправляется последнее известное состояние заказа.
+func (w *Watcher) Subscribe(orderID, lastSequence uint64) (*Subscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil, ErrShuttingDown
+	}
+
+	h, ok := w.hubs[orderID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		h = &hub{
+			orderID: orderID,
+			cancel:  cancel,
+			subs:    make(map[*Subscription]struct{}),
+		}
+		w.hubs[orderID] = h
+		go w.run(ctx, h)
+	}
+
+	sub := &Subscription{
+		watcher:      w,
+		hub:          h,
+		events:       make(chan Event, replayBufferSize+subscriberQueueSize),
+		lastSequence: lastSequence,
+	}
+	h.subs[sub] = struct{}{}
+
+	for _, ev := range replay(h.buffer, lastSequence) {
+		sub.events <- ev
+		sub.lastSequence = ev.Sequence
+	}
+	return sub, nil
+}
+
+// replay выбирает события для нового подписчика.
+func replay(buffer []Event, lastSequence uint64) []Event {
+	if len(buffer) == 0 {
+		return nil
+	}
+	latest := buffer[len(buffer)-1]
+	if lastSequence >= latest.Sequence {
+		return nil
+	}
+	// Без Last-Event-ID или при разрыве в буфере достаточно последнего
+	// события: оно содержит заказ целиком
+	if lastSequence == 0 || buffer[0].Sequence > lastSequence+1 {
+		return []Event{latest}
+	}
+	for i, ev := range buffer {
+		if ev.Sequence > lastSequence {
+			return buffer[i:]
+		}
+	}
+	return nil
+}
+
+// Close завершает все подписки, например при остановке сервиса.
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	for id, h := range w.hubs {
+		h.cancel()
+		for sub := range h.subs {
+			w.closeSubscription(sub, ErrShuttingDown)
+		}
+		delete(w.hubs, id)
+	}
+}
+
+// closeSubscription вызывается под w.mu.
+func (w *Watcher) closeSubscription(sub *Subscription, err error) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	sub.err = err
+	close(sub.events)
+	delete(sub.hub.subs, sub)
+}
+
+func (w *Watcher) publish(h *hub, ev Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(h.buffer); n > 0 && ev.Sequence <= h.buffer[n-1].Sequence {
+		return
+	}
+	h.buffer = append(h.buffer, ev)
+	if len(h.buffer) > replayBufferSize {
+		h.buffer = h.buffer[len(h.buffer)-replayBufferSize:]
+	}
+
+	for sub := range h.subs {
+		if ev.Sequence <= sub.lastSequence {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+			sub.lastSequence = ev.Sequence
+		default:
+			w.closeSubscription(sub, ErrLagging)
+		}
+	}
+	if len(h.subs) == 0 && w.hubs[h.orderID] == h {
+		h.cancel()
+		delete(w.hubs, h.orderID)
+	}
+}
+
+// finish завершает все подписки заказа с причиной err.
+func (w *Watcher) finish(h *hub, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	h.cancel()
+	for sub := range h.subs {
+		w.closeSubscription(sub, err)
+	}
+	if w.hubs[h.orderID] == h {
+		delete(w.hubs, h.orderID)
+	}
+}
+
+// run держит поток WatchOrder и переоткрывает его после обрывов, продолжая
+// с последнего полученного события.
+func (w *Watcher) run(ctx context.Context, h *hub) {
+	var after uint64
+	attempts := 0
+
+	for {
+		err := w.stream(ctx, h, &after, &attempts)
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err == nil:
+			// OrderService закрывает поток, когда заказ завершён
+			w.finish(h, nil)
+			return
+		case status.Code(err) != codes.Unavailable || attempts >= maxReconnectAttempts:
+			slog.Warn("order watch stream failed",
+				slog.Uint64("order_id", h.orderID), slog.Any("error", err))
+			w.finish(h, err)
+			return
+		}
+
+		attempts++
+		if sleep(ctx, reconnectDelay(attempts)) != nil {
+			return
+		}
+	}
+}
+
+func (w *Watcher) stream(ctx context.Context, h *hub, after *uint64, attempts *int) error {
+	stream, err := w.client.WatchOrder(ctx, &pbOrders.WatchOrderRequest{
+		Id:            h.orderID,
+		AfterSequence: *after,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		*attempts = 0
+		if msg.GetOrder() == nil || msg.Sequence <= *after {
+			continue
+		}
+		*after = msg.Sequence
+
+		w.publish(h, Event{
+			Sequence:   msg.Sequence,
+			Order:      msg.Order,
+			OccurredAt: time.UnixMilli(msg.OccurredAtUnixMs),
+		})
+
+		// Не ждём закрытия потока сервером: после конечного статуса
+		// изменений больше не будет
+		if order.IsFinalStatus(order.NormalizeStatus(msg.Order.Status)) {
+			return nil
+		}
+	}
+}
+
+func reconnectDelay(attempt int) time.Duration {
+	ceiling := reconnectBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > maxReconnectBackoff {
+		ceiling = maxReconnectBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,227 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anyviewww/bff-service/internal/testing/fakes"
+	pbOrders "github.com/anyviewww/bff-service/proto/orders"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestWatcher(t *testing.T) (*Watcher, *fakes.Server) {
+	t.Helper()
+
+	backend := fakes.NewServer()
+	t.Cleanup(backend.Close)
+	conn, err := backend.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	backend.Orders.Seed(&pbOrders.OrderResponse{Id: 1, UserId: 5, Items: []int64{1}, Status: "created"})
+	w := NewWatcher(pbOrders.NewOrderServiceClient(conn))
+	t.Cleanup(w.Close)
+	return w, backend
+}
+
+func subscribe(t *testing.T, w *Watcher, orderID, lastSequence uint64) *Subscription {
+	t.Helper()
+
+	sub, err := w.Subscribe(orderID, lastSequence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sub.Close)
+	return sub
+}
+
+func next(t *testing.T, sub *Subscription) (Event, bool) {
+	t.Helper()
+
+	select {
+	case ev, ok := <-sub.Events():
+		return ev, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return Event{}, false
+	}
+}
+
+func expectSequence(t *testing.T, sub *Subscription, want uint64) Event {
+	t.Helper()
+
+	ev, ok := next(t, sub)
+	if !ok {
+		t.Fatalf("subscription closed with %v, want event %d", sub.Err(), want)
+	}
+	if ev.Sequence != want {
+		t.Fatalf("event sequence = %d, want %d", ev.Sequence, want)
+	}
+	return ev
+}
+
+func waitForWatchers(t *testing.T, backend *fakes.Server, orderID uint64, want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for backend.Orders.Watchers(orderID) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("WatchOrder streams = %d, want %d", backend.Orders.Watchers(orderID), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatcherSharesStream(t *testing.T) {
+	w, backend := newTestWatcher(t)
+
+	first := subscribe(t, w, 1, 0)
+	expectSequence(t, first, 1)
+	second := subscribe(t, w, 1, 0)
+	// Второй подписчик получает текущее состояние из буфера
+	expectSequence(t, second, 1)
+
+	backend.Orders.SetStatus(1, "confirmed")
+	for _, sub := range []*Subscription{first, second} {
+		if ev := expectSequence(t, sub, 2); ev.Order.Status != "confirmed" {
+			t.Errorf("status = %q, want confirmed", ev.Order.Status)
+		}
+	}
+	if n := len(backend.Orders.Calls("WatchOrder")); n != 1 {
+		t.Errorf("WatchOrder calls = %d, want 1", n)
+	}
+
+	// Поток закрывается, когда уходит последний подписчик
+	first.Close()
+	second.Close()
+	waitForWatchers(t, backend, 1, 0)
+}
+
+func TestWatcherFinalStatus(t *testing.T) {
+	w, backend := newTestWatcher(t)
+
+	sub := subscribe(t, w, 1, 0)
+	expectSequence(t, sub, 1)
+	backend.Orders.SetStatus(1, "cancelled")
+	expectSequence(t, sub, 2)
+
+	if _, ok := next(t, sub); ok {
+		t.Fatal("subscription is open after the final status")
+	}
+	if err := sub.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestWatcherResumeWithNewStream(t *testing.T) {
+	w, backend := newTestWatcher(t)
+
+	sub := subscribe(t, w, 1, 0)
+	expectSequence(t, sub, 1)
+	backend.Orders.SetStatus(1, "confirmed")
+	expectSequence(t, sub, 2)
+	sub.Close()
+	waitForWatchers(t, backend, 1, 0)
+
+	// Новый поток начинается с события 2, которое у подписчика уже есть
+	resumed := subscribe(t, w, 1, 2)
+	waitForWatchers(t, backend, 1, 1)
+	backend.Orders.SetStatus(1, "cooking")
+	expectSequence(t, resumed, 3)
+
+	// Подписчик, отставший сильнее, получает пропущенное из буфера
+	behind := subscribe(t, w, 1, 1)
+	expectSequence(t, behind, 2)
+	expectSequence(t, behind, 3)
+}
+
+func TestWatcherBackendError(t *testing.T) {
+	w, backend := newTestWatcher(t)
+	backend.Orders.Fail("WatchOrder", fakes.Fault{Code: codes.PermissionDenied})
+
+	sub := subscribe(t, w, 1, 0)
+	if _, ok := next(t, sub); ok {
+		t.Fatal("subscription is open after a backend error")
+	}
+	if code := status.Code(sub.Err()); code != codes.PermissionDenied {
+		t.Errorf("Err() = %v, want PermissionDenied", sub.Err())
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	w, _ := newTestWatcher(t)
+
+	sub := subscribe(t, w, 1, 0)
+	expectSequence(t, sub, 1)
+	w.Close()
+
+	if _, ok := next(t, sub); ok {
+		t.Fatal("subscription is open after Close")
+	}
+	if err := sub.Err(); err != ErrShuttingDown {
+		t.Errorf("Err() = %v, want ErrShuttingDown", err)
+	}
+	if _, err := w.Subscribe(1, 0); err != ErrShuttingDown {
+		t.Errorf("Subscribe after Close: err = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	buffer := []Event{{Sequence: 3}, {Sequence: 4}, {Sequence: 5}}
+
+	tests := []struct {
+		name         string
+		buffer       []Event
+		lastSequence uint64
+		want         []uint64
+	}{
+		{name: "empty buffer", lastSequence: 2},
+		{name: "new subscriber", buffer: buffer, want: []uint64{5}},
+		{name: "up to date", buffer: buffer, lastSequence: 5},
+		{name: "ahead of buffer", buffer: buffer, lastSequence: 7},
+		{name: "missed events", buffer: buffer, lastSequence: 3, want: []uint64{4, 5}},
+		{name: "right before buffer", buffer: buffer, lastSequence: 2, want: []uint64{3, 4, 5}},
+		{name: "gap", buffer: buffer, lastSequence: 1, want: []uint64{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint64
+			for _, ev := range replay(tt.buffer, tt.lastSequence) {
+				got = append(got, ev.Sequence)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("replay = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("replay = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, reconnectBackoff},
+		{2, 2 * reconnectBackoff},
+		{3, 4 * reconnectBackoff},
+		{10, maxReconnectBackoff},
+		// Сдвиг переполняется, потолок всё равно maxReconnectBackoff
+		{100, maxReconnectBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := reconnectDelay(tt.attempt); d < 0 || d > tt.ceiling {
+				t.Fatalf("reconnectDelay(%d) = %v, want within [0, %v]", tt.attempt, d, tt.ceiling)
+			}
+		}
+	}
+}
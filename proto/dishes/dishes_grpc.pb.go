@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.14.0
+// source: proto/dishes/dishes.proto
 
 package dishes
 
@@ -14,6 +18,10 @@ import (
 // Requires gRPC-Go v1.32.0 or later.
 const _ = grpc.SupportPackageIsVersion7
 
+const (
+	DishService_GetDishes_FullMethodName = "/dishes.DishService/GetDishes"
+)
+
 // DishServiceClient is the client API for DishService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
@@ -31,7 +39,7 @@ func NewDishServiceClient(cc grpc.ClientConnInterface) DishServiceClient {
 
 func (c *dishServiceClient) GetDishes(ctx context.Context, in *DishRequest, opts ...grpc.CallOption) (*DishesResponse, error) {
 	out := new(DishesResponse)
-	err := c.cc.Invoke(ctx, "/dishes.DishService/GetDishes", in, out, opts...)
+	err := c.cc.Invoke(ctx, DishService_GetDishes_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +84,7 @@ func _DishService_GetDishes_Handler(srv interface{}, ctx context.Context, dec fu
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/dishes.DishService/GetDishes",
+		FullMethod: DishService_GetDishes_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(DishServiceServer).GetDishes(ctx, req.(*DishRequest))